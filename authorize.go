@@ -0,0 +1,126 @@
+package eventsourcing
+
+import (
+	"strings"
+	"sync"
+)
+
+// Authorization
+//
+// Manager 를 가진 호출자는 어떤 partition 에든 어떤 EventType 이든 Put 할 수 있다.
+// Authorizer 는 Put 전에 (actor, pk, event type, request) 로, 조회 전에 (actor, pk) 로 요청을 허용할지 판단한다.
+// 허용하지 않은 요청은 Unauthorized Code 의 에러(ErrUnauthorized)로 돌려준다.
+// manager.NewAuthorizedManager 로 actor 마다 Authorizer 를 거치는 Manager 를 만든다.
+// RoleTable 은 actor 에 role 을, role 에 Permission 을 부여하는 기본 구현체이다.
+
+// Authorizer | actor 의 요청을 허용할지 판단하는 인터페이스, 허용하면 nil
+type Authorizer[R any] interface {
+	AuthorizePut(actor Actor, pk PartitionKey, et *EventType, req *R) error // Put, Validate 전에 호출, Validate 는 req 가 nil
+	AuthorizeRead(actor Actor, pk PartitionKey) error                       // event, state 조회 전에 호출
+}
+
+// Action | Permission 이 허용하는 동작
+type Action string
+
+const (
+	ActionRead Action = "read" // event, state 조회
+	ActionPut  Action = "put"  // event 저장
+)
+
+// Permission | role 에 부여하는 권한, 빈 값은 모두를 허용한다.
+type Permission struct {
+	Action             Action
+	Domain             Domain    // ActionPut 에서 허용하는 EventType 의 domain
+	EventName          EventName // ActionPut 에서 허용하는 EventType 의 name
+	PartitionKeyPrefix string    // 허용하는 partition key 의 prefix
+}
+
+// Allows | action 을 pk 에 (ActionPut 이라면 et 로) 수행해도 되는지 여부
+func (p Permission) Allows(action Action, pk PartitionKey, et *EventType) bool {
+	if p.Action != "" && p.Action != action {
+		return false
+	}
+	if !strings.HasPrefix(string(pk), p.PartitionKeyPrefix) {
+		return false
+	}
+	if action == ActionPut && et != nil {
+		if p.Domain != "" && p.Domain != et.Domain {
+			return false
+		}
+		if p.EventName != "" && p.EventName != et.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// Role | Permission 의 묶음
+type Role string
+
+// RoleTable | actor 에 부여한 role 의 Permission 으로 판단하는 Authorizer
+type RoleTable[R any] struct {
+	grants map[Role][]Permission
+	roles  map[Actor][]Role
+	locker sync.RWMutex
+}
+
+func NewRoleTable[R any]() *RoleTable[R] {
+	return &RoleTable[R]{
+		grants: make(map[Role][]Permission),
+		roles:  make(map[Actor][]Role),
+	}
+}
+
+// Grant | role 에 permissions 를 추가한다.
+func (t *RoleTable[R]) Grant(role Role, permissions ...Permission) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	t.grants[role] = append(t.grants[role], permissions...)
+}
+
+// Assign | actor 에 roles 를 추가한다.
+func (t *RoleTable[R]) Assign(actor Actor, roles ...Role) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	t.roles[actor] = append(t.roles[actor], roles...)
+}
+
+// Unassign | actor 의 role 을 뺀다.
+func (t *RoleTable[R]) Unassign(actor Actor, role Role) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	roles := t.roles[actor][:0]
+	for _, r := range t.roles[actor] {
+		if r != role {
+			roles = append(roles, r)
+		}
+	}
+	t.roles[actor] = roles
+}
+
+func (t *RoleTable[R]) AuthorizePut(actor Actor, pk PartitionKey, et *EventType, _ *R) error {
+	if t.allows(actor, ActionPut, pk, et) {
+		return nil
+	}
+	return NewUnauthorizedError(nil, actor, pk, et)
+}
+
+func (t *RoleTable[R]) AuthorizeRead(actor Actor, pk PartitionKey) error {
+	if t.allows(actor, ActionRead, pk, nil) {
+		return nil
+	}
+	return NewUnauthorizedError(nil, actor, pk, nil)
+}
+
+func (t *RoleTable[R]) allows(actor Actor, action Action, pk PartitionKey, et *EventType) bool {
+	t.locker.RLock()
+	defer t.locker.RUnlock()
+	for _, role := range t.roles[actor] {
+		for _, p := range t.grants[role] {
+			if p.Allows(action, pk, et) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package eventsourcing
+
+import (
+	"fmt"
+	"github.com/rs/xid"
+	"sync"
+	"time"
+)
+
+// Event 생성과 Rule 판단에 사용하는 시간, Event Id 발급을 주입할 수 있도록 인터페이스로 정의한다.
+//
+// [Clock]
+// - 현재 시간을 알려주는 인터페이스
+// - 기본값은 SystemClock 이며, 테스트에서는 FakeClock 으로 시간을 직접 움직일 수 있다.
+//
+// [IdGenerator]
+// - EventId 를 발급하는 인터페이스
+// - 기본값은 XidGenerator 이며, 테스트에서는 SequenceIdGenerator 로 재현 가능한 Id 를 만들 수 있다.
+
+var (
+	DefaultClock       Clock       = SystemClock{}
+	DefaultIdGenerator IdGenerator = XidGenerator{}
+)
+
+// Clock | 현재 시간을 알려주는 인터페이스
+type Clock interface {
+	Now() time.Time
+}
+
+// IdGenerator | EventId 를 발급하는 인터페이스, 발급한 Id 는 sorted 해야 한다.
+type IdGenerator interface {
+	NewId() EventId
+}
+
+// SystemClock | 실제 시간을 UTC 로 알려주는 Clock
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// FakeClock | 직접 시간을 정하고 움직이는 Clock, 시간에 따른 동작을 기다림 없이 테스트할 때 사용한다.
+type FakeClock struct {
+	now    time.Time
+	locker sync.RWMutex
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now.UTC()}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.locker.RLock()
+	defer c.locker.RUnlock()
+	return c.now
+}
+
+// Set | 현재 시간을 now 로 바꾼다.
+func (c *FakeClock) Set(now time.Time) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	c.now = now.UTC()
+}
+
+// Advance | 현재 시간을 d 만큼 이동시킨다.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// XidGenerator | xid 로 EventId 를 발급하는 IdGenerator
+type XidGenerator struct{}
+
+func (XidGenerator) NewId() EventId {
+	return EventId(xid.New().String())
+}
+
+// SequenceIdGenerator | prefix 와 증가하는 번호로 EventId 를 발급하는 IdGenerator, 같은 순서로 호출하면 같은 Id 가 나온다.
+type SequenceIdGenerator struct {
+	prefix string
+	seq    int64
+	locker sync.Mutex
+}
+
+func NewSequenceIdGenerator(prefix string) *SequenceIdGenerator {
+	return &SequenceIdGenerator{prefix: prefix}
+}
+
+func (g *SequenceIdGenerator) NewId() EventId {
+	g.locker.Lock()
+	defer g.locker.Unlock()
+	g.seq++
+	return EventId(fmt.Sprintf("%s%012d", g.prefix, g.seq)) // 자리수를 맞춰야 문자열로 정렬이 된다
+}
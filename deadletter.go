@@ -0,0 +1,36 @@
+package eventsourcing
+
+import "time"
+
+// Poison Event Quarantine
+//
+// 잘못 만들어진 event 하나 때문에 replay 가 계속 실패하면, 그 partition 의 snapshot 은 더 이상 갱신되지 않는다.
+// Rule.FailurePolicy 로 replay 중 실패한 event 를 어떻게 다룰지 정한다.
+// - HaltOnFailure : 실패한 event 에서 replay 를 멈추고 에러를 돌려준다. (기본)
+// - QuarantineOnFailure : 실패한 event 를 DeadLetterStorage 에 격리하고, 건너뛰고 replay 를 계속한다.
+//   격리된 event 는 이후의 replay 에서도 건너뛴다. (정정 event 를 넣거나, Process 를 고친 뒤 격리를 해제할 수 있다.)
+// Rule.FailureRetries 를 지정하면 policy 를 따르기 전에 실패한 event 를 다시 시도한다.
+
+// FailurePolicy | replay 중 event 적용에 실패했을 때의 처리 방식
+type FailurePolicy int
+
+const (
+	HaltOnFailure       FailurePolicy = iota // 실패한 event 에서 replay 를 멈춤
+	QuarantineOnFailure                      // 실패한 event 를 격리하고 건너뜀
+)
+
+// DeadLetter | replay 에 실패해서 격리된 event
+type DeadLetter[R any] struct {
+	PartitionKey  PartitionKey `json:"partitionKey"`
+	EventNo       int          `json:"eventNo"`
+	Event         *Event[R]    `json:"event"`
+	Error         string       `json:"error"`                 // 마지막 실패의 에러 메세지
+	Attempts      int          `json:"attempts"`              // 적용을 시도한 횟수
+	QuarantinedAt time.Time    `json:"quarantinedAt"`         // 격리된 시간
+	CorrectedBy   EventId      `json:"correctedBy,omitempty"` // 정정 event 의 id, 정정되었어도 격리된 event 는 계속 건너뛴다.
+}
+
+// Corrected | 정정 event 로 처리되었는지 여부
+func (d *DeadLetter[R]) Corrected() bool {
+	return d.CorrectedBy != ""
+}
@@ -0,0 +1,97 @@
+package eventsourcing
+
+// Storage Decorator
+//
+// retry, encryption, integrity 처럼 storage 를 감싸는 decorator 는 필수 인터페이스만 구현하고,
+// 감싼 storage 가 구현한 선택 인터페이스(EventIdRangeStorage, StateSnapshotHistoryStorage) 는 DecorateEventStorage, DecorateSnapshotStorage 로 이어받는다.
+// 선택 인터페이스가 늘어나면 decorator 마다 고치지 않고 여기에만 추가한다.
+
+var _ EventIdRangeStorage[any] = &decoratedEventStorage[any]{}
+
+// EventStorageHooks | 감싼 storage 의 선택 인터페이스를 호출할 때 decorator 가 끼워넣는 함수, nil 이면 그대로 호출한다.
+type EventStorageHooks[R any] struct {
+	Call   func(call func() error) error                 // 호출을 감싼다 (ex. 재시도)
+	Events func(events []*Event[R]) ([]*Event[R], error) // 조회에 성공한 event 를 바꾼다 (ex. 복호화)
+}
+
+func (h EventStorageHooks[R]) call(call func() error) error {
+	if h.Call == nil {
+		return call()
+	}
+	return h.Call(call)
+}
+
+// DecorateEventStorage | inner 를 감싼 decorator 에 inner 가 구현한 선택 인터페이스를 붙여서 돌려준다.
+func DecorateEventStorage[R any](decorator, inner EventStorage[R], hooks EventStorageHooks[R]) EventStorage[R] {
+	ranged, ok := inner.(EventIdRangeStorage[R])
+	if !ok {
+		return decorator
+	}
+	return &decoratedEventStorage[R]{EventStorage: decorator, ranged: ranged, hooks: hooks}
+}
+
+// decoratedEventStorage | 필수 인터페이스는 decorator 로, 선택 인터페이스는 hook 을 거쳐 감싼 storage 로 보내는 EventStorage
+type decoratedEventStorage[R any] struct {
+	EventStorage[R]
+	ranged EventIdRangeStorage[R]
+	hooks  EventStorageHooks[R]
+}
+
+func (a *decoratedEventStorage[R]) GetEventsBetweenIds(from, to EventId) (events []*Event[R], err error) {
+	err = a.hooks.call(func() error {
+		events, err = a.ranged.GetEventsBetweenIds(from, to)
+		return err
+	})
+	if err != nil || a.hooks.Events == nil {
+		return events, err
+	}
+	return a.hooks.Events(events)
+}
+
+// SnapshotStorageHooks | 감싼 storage 의 선택 인터페이스를 호출할 때 decorator 가 끼워넣는 함수, nil 이면 그대로 호출한다.
+type SnapshotStorageHooks[S CommonState[R], R any] struct {
+	Call  func(call func() error) error                                   // 호출을 감싼다 (ex. 재시도)
+	State func(pk PartitionKey, state *State[S, R]) (*State[S, R], error) // 조회에 성공한 snapshot 을 바꾼다 (ex. 복호화)
+}
+
+func (h SnapshotStorageHooks[S, R]) call(call func() error) error {
+	if h.Call == nil {
+		return call()
+	}
+	return h.Call(call)
+}
+
+// DecorateSnapshotStorage | inner 를 감싼 decorator 에 inner 가 구현한 선택 인터페이스를 붙여서 돌려준다.
+func DecorateSnapshotStorage[S CommonState[R], R any](decorator, inner StateSnapshotStorage[S, R], hooks SnapshotStorageHooks[S, R]) StateSnapshotStorage[S, R] {
+	history, ok := inner.(StateSnapshotHistoryStorage[S, R])
+	if !ok {
+		return decorator
+	}
+	return &decoratedSnapshotStorage[S, R]{StateSnapshotStorage: decorator, history: history, hooks: hooks}
+}
+
+// decoratedSnapshotStorage | 필수 인터페이스는 decorator 로, 선택 인터페이스는 hook 을 거쳐 감싼 storage 로 보내는 StateSnapshotStorage
+type decoratedSnapshotStorage[S CommonState[R], R any] struct {
+	StateSnapshotStorage[S, R]
+	history StateSnapshotHistoryStorage[S, R]
+	hooks   SnapshotStorageHooks[S, R]
+}
+
+func (a *decoratedSnapshotStorage[S, R]) GetSnapshotAtOrBefore(pk PartitionKey, eventNo int) (state *State[S, R], err error) {
+	err = a.hooks.call(func() error {
+		state, err = a.history.GetSnapshotAtOrBefore(pk, eventNo)
+		return err
+	})
+	if err != nil || a.hooks.State == nil {
+		return state, err
+	}
+	return a.hooks.State(pk, state)
+}
+
+func (a *decoratedSnapshotStorage[S, R]) GetSnapshotHistory(pk PartitionKey) (infos []SnapshotInfo, err error) {
+	err = a.hooks.call(func() error {
+		infos, err = a.history.GetSnapshotHistory(pk)
+		return err
+	})
+	return infos, err
+}
@@ -0,0 +1,100 @@
+package eventsourcing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Difference | 두 값을 비교했을 때 달라진 field 하나의 정보
+type Difference struct {
+	Path     string // json 기준 field 경로, ex) lastEvent.eventNo
+	Expected any    // 기대한 값, field 가 없으면 nil
+	Actual   any    // 실제 값, field 가 없으면 nil
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: expected(%s), actual(%s)", d.Path, JsonString(d.Expected), JsonString(d.Actual))
+}
+
+// DiffJson | 두 값을 json 으로 변환한 뒤 field 단위로 비교한다. ignores 에 들어간 경로(하위 포함)는 비교하지 않는다.
+func DiffJson(expected, actual any, ignores ...string) ([]Difference, error) {
+	e, err := toJsonValue(expected)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toJsonValue(actual)
+	if err != nil {
+		return nil, err
+	}
+	diffs := make([]Difference, 0)
+	diffJsonValue("", e, a, ignores, &diffs)
+	return diffs, nil
+}
+
+func toJsonValue(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var out any
+	if err = decoder.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func diffJsonValue(path string, expected, actual any, ignores []string, diffs *[]Difference) {
+	for _, ignore := range ignores {
+		if path == ignore || strings.HasPrefix(path, ignore+".") || strings.HasPrefix(path, ignore+"[") {
+			return
+		}
+	}
+
+	switch e := expected.(type) {
+	case map[string]any:
+		a, ok := actual.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(e)+len(a))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		for k := range a {
+			if _, ok := e[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			diffJsonValue(joinJsonPath(path, k), e[k], a[k], ignores, diffs)
+		}
+		return
+	case []any:
+		a, ok := actual.([]any)
+		if !ok || len(a) != len(e) {
+			break
+		}
+		for i := range e {
+			diffJsonValue(fmt.Sprintf("%s[%d]", path, i), e[i], a[i], ignores, diffs)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		*diffs = append(*diffs, Difference{Path: path, Expected: expected, Actual: actual})
+	}
+}
+
+func joinJsonPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
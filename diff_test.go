@@ -0,0 +1,64 @@
+package eventsourcing
+
+import (
+	"testing"
+)
+
+func TestDiffJson(t *testing.T) {
+	type inner struct {
+		No   int    `json:"no"`
+		Name string `json:"name"`
+	}
+	type value struct {
+		Amount int      `json:"amount"`
+		Tags   []string `json:"tags"`
+		Inner  *inner   `json:"inner"`
+	}
+	tests := []struct {
+		name     string
+		expected any
+		actual   any
+		ignores  []string
+		paths    []string
+	}{
+		{
+			name:     "same",
+			expected: value{Amount: 1, Tags: []string{"a"}, Inner: &inner{No: 1}},
+			actual:   value{Amount: 1, Tags: []string{"a"}, Inner: &inner{No: 1}},
+		},
+		{
+			name:     "nested_field",
+			expected: value{Amount: 1, Inner: &inner{No: 1, Name: "a"}},
+			actual:   value{Amount: 2, Inner: &inner{No: 1, Name: "b"}},
+			paths:    []string{"amount", "inner.name"},
+		},
+		{
+			name:     "slice_element",
+			expected: value{Tags: []string{"a", "b"}},
+			actual:   value{Tags: []string{"a", "c"}},
+			paths:    []string{"tags[1]"},
+		},
+		{
+			name:     "ignored",
+			expected: value{Amount: 1, Inner: &inner{No: 1}},
+			actual:   value{Amount: 1, Inner: nil},
+			ignores:  []string{"inner"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs, err := DiffJson(tt.expected, tt.actual, tt.ignores...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(diffs) != len(tt.paths) {
+				t.Fatalf("DiffJson() = %v, want paths %v", diffs, tt.paths)
+			}
+			for i, d := range diffs {
+				if d.Path != tt.paths[i] {
+					t.Errorf("DiffJson()[%d].Path = %s, want %s", i, d.Path, tt.paths[i])
+				}
+			}
+		})
+	}
+}
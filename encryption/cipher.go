@@ -0,0 +1,63 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	es "eventsourcing"
+)
+
+// Payload Encryption
+//
+// KeyStore 의 partition 별 data key 로 AES-256-GCM 암호화를 한다.
+// 암호문은 nonce 뒤에 붙여서 EncryptedPayload.Data 에 담고, 어떤 key 로 암호화했는지 KeyId 에 남긴다.
+// additional data 로 pk(와 EventId) 를 묶어서, 암호문을 다른 partition 이나 event 로 옮기면 복호화에 실패한다.
+
+var errShortPayload = es.Permanent(errors.New("encrypted payload is too short"))
+
+func seal(keys es.KeyStore, pk es.PartitionKey, plain, additional []byte) (*es.EncryptedPayload, error) {
+	keyId, key, err := keys.DataKey(pk)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &es.EncryptedPayload{
+		KeyId: keyId,
+		Data:  aead.Seal(nonce, nonce, plain, additional),
+	}, nil
+}
+
+// open | payload 를 복호화한다. key 가 지워졌으면 es.ErrKeyShredded 를, 복호화에 실패하면 다시 시도하지 않을 에러를 돌려준다.
+func open(keys es.KeyStore, pk es.PartitionKey, payload *es.EncryptedPayload, additional []byte) ([]byte, error) {
+	key, err := keys.GetKey(pk, payload.KeyId)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload.Data) < aead.NonceSize() {
+		return nil, errShortPayload
+	}
+	nonce, data := payload.Data[:aead.NonceSize()], payload.Data[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, data, additional)
+	return plain, es.Permanent(err) // 암호문이나 key 가 맞지 않으면 다시 시도해도 실패
+
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,215 @@
+package encryption
+
+import (
+	"encoding/json"
+	"errors"
+	es "eventsourcing"
+	"time"
+)
+
+// Storage Decorator
+//
+// EventStorage 는 저장할 때 Event.Request 를 암호화해서 Event.Encrypted 에 담고, 조회할 때 복호화한다.
+// - 복호화한 event 에도 Encrypted 를 남긴다. 서명과 hash 는 저장된 암호문에 걸려 있다.
+// StateSnapshotStorage 는 저장할 때 State 전체를 암호화한다. 감싼 storage 에는 LastEvent 만 채운 빈 state 가 저장된다.
+// - 조회한 event 의 data key 가 지워졌으면 Request 가 nil 이고 Encrypted 가 남은 event(Event.Shredded) 를 돌려준다.
+// - 조회한 snapshot 의 data key 가 지워졌으면 snapshot 이 없는 것(nil)으로 돌려준다. manager 는 event 를 처음부터 replay 한다.
+// - StateSnapshotHistoryStorage 를 감쌀 때는 *S 가 LastEventSetter 를 구현해야 history 의 eventNo 가 유지된다.
+// 감싼 storage 에 저장된 event, snapshot 은 바꾸지 않고 복사본을 돌려준다.
+// 감싼 storage 의 선택 인터페이스는 eventsourcing.DecorateEventStorage, DecorateSnapshotStorage 로 이어받고, 조회 결과는 복호화한다.
+
+var _ es.EventStorage[any] = &EventStorage[any]{}
+
+// EventStorage | Event.Request 를 암호화해서 저장하는 EventStorage
+type EventStorage[R any] struct {
+	storage es.EventStorage[R]
+	keys    es.KeyStore
+}
+
+// NewEventStorage | storage 에 저장하는 Request 를 keys 의 data key 로 암호화한다.
+func NewEventStorage[R any](storage es.EventStorage[R], keys es.KeyStore) es.EventStorage[R] {
+	base := &EventStorage[R]{storage: storage, keys: keys}
+	return es.DecorateEventStorage[R](base, storage, es.EventStorageHooks[R]{
+		Events: func(events []*es.Event[R]) ([]*es.Event[R], error) { return base.decryptAll(events, nil) },
+	})
+}
+
+func (a *EventStorage[R]) IncreaseEventNo(pk es.PartitionKey) (int, error) {
+	return a.storage.IncreaseEventNo(pk)
+}
+
+func (a *EventStorage[R]) AddEvent(e *es.Event[R]) error {
+	encrypted, err := a.encrypt(e)
+	if err != nil {
+		return err
+	}
+	return a.storage.AddEvent(encrypted)
+}
+
+func (a *EventStorage[R]) AppendEvent(ea *es.EventAppend[R]) (*es.Event[R], bool, error) {
+	encrypted, err := a.encrypt(ea.Event)
+	if err != nil {
+		return nil, false, err
+	}
+	stored := *ea
+	stored.Event = encrypted
+	e, appended, err := a.storage.AppendEvent(&stored)
+	e, err = a.decrypt(e, err)
+	return e, appended, err
+}
+
+// encrypt | Request 를 암호화한 복사본을 돌려준다. 복호화해서 읽은 event 는 남아있는 암호문을 그대로 쓴다.
+func (a *EventStorage[R]) encrypt(e *es.Event[R]) (*es.Event[R], error) {
+	if e.Request == nil {
+		return e, nil
+	}
+	if e.Encrypted != nil {
+		stored := *e
+		stored.Request = nil
+		return &stored, nil
+	}
+	plain, err := json.Marshal(e.Request)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := seal(a.keys, e.PartitionKey, plain, eventAdditional(e))
+	if err != nil {
+		return nil, err
+	}
+	stored := *e
+	stored.Request = nil
+	stored.Encrypted = encrypted
+	return &stored, nil
+}
+
+func (a *EventStorage[R]) GetEvent(id es.EventId) (*es.Event[R], error) {
+	return a.decrypt(a.storage.GetEvent(id))
+}
+
+func (a *EventStorage[R]) GetEvents(pk es.PartitionKey) ([]*es.Event[R], error) {
+	return a.decryptAll(a.storage.GetEvents(pk))
+}
+
+func (a *EventStorage[R]) GetEventsAfterEventNo(pk es.PartitionKey, eno int) ([]*es.Event[R], error) {
+	return a.decryptAll(a.storage.GetEventsAfterEventNo(pk, eno))
+}
+
+func (a *EventStorage[R]) GetLastEvent(pk es.PartitionKey) (*es.Event[R], error) {
+	return a.decrypt(a.storage.GetLastEvent(pk))
+}
+
+func (a *EventStorage[R]) GetEventByIdempotencyKey(pk es.PartitionKey, key string, since time.Time) (*es.Event[R], error) {
+	return a.decrypt(a.storage.GetEventByIdempotencyKey(pk, key, since))
+}
+
+func (a *EventStorage[R]) ListPartitions(cursor string, limit int) ([]*es.PartitionStat, string, error) {
+	return a.storage.ListPartitions(cursor, limit)
+}
+
+// decrypt | 암호화된 event 를 복호화한 복사본을 돌려준다. data key 가 지워졌으면 그대로 돌려준다.
+func (a *EventStorage[R]) decrypt(e *es.Event[R], err error) (*es.Event[R], error) {
+	if err != nil || e == nil || e.Encrypted == nil {
+		return e, err
+	}
+	plain, err := open(a.keys, e.PartitionKey, e.Encrypted, eventAdditional(e))
+	if errors.Is(err, es.ErrKeyShredded) {
+		return e, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	request := new(R)
+	if err = json.Unmarshal(plain, request); err != nil {
+		return nil, err
+	}
+	decrypted := *e
+	decrypted.Request = request
+	return &decrypted, nil
+}
+
+func (a *EventStorage[R]) decryptAll(events []*es.Event[R], err error) ([]*es.Event[R], error) {
+	if err != nil {
+		return nil, err
+	}
+	decrypted := make([]*es.Event[R], len(events))
+	for i, e := range events {
+		if decrypted[i], err = a.decrypt(e, nil); err != nil {
+			return nil, err
+		}
+	}
+	return decrypted, nil
+}
+
+func eventAdditional[R any](e *es.Event[R]) []byte {
+	return []byte(string(e.PartitionKey) + "/" + string(e.EventId))
+}
+
+// SnapshotStorage | State 를 암호화해서 저장하는 StateSnapshotStorage
+type SnapshotStorage[S es.CommonState[R], R any] struct {
+	storage es.StateSnapshotStorage[S, R]
+	keys    es.KeyStore
+}
+
+// NewSnapshotStorage | storage 에 저장하는 State 를 keys 의 data key 로 암호화한다.
+func NewSnapshotStorage[S es.CommonState[R], R any](storage es.StateSnapshotStorage[S, R], keys es.KeyStore) es.StateSnapshotStorage[S, R] {
+	base := &SnapshotStorage[S, R]{storage: storage, keys: keys}
+	return es.DecorateSnapshotStorage[S, R](base, storage, es.SnapshotStorageHooks[S, R]{
+		State: func(pk es.PartitionKey, state *es.State[S, R]) (*es.State[S, R], error) {
+			return base.unseal(pk)(state, nil)
+		},
+	})
+}
+
+func (a *SnapshotStorage[S, R]) SaveSnapshot(pk es.PartitionKey, state *es.State[S, R]) error {
+	if state == nil || state.State() == nil {
+		return a.storage.SaveSnapshot(pk, state)
+	}
+	plain, err := json.Marshal(state.State())
+	if err != nil {
+		return err
+	}
+	sealed, err := seal(a.keys, pk, plain, []byte(pk))
+	if err != nil {
+		return err
+	}
+
+	// storage 가 snapshot 의 eventNo, eventAt 을 알 수 있도록 Request 를 뺀 LastEvent 만 남긴다
+	placeholder := new(S)
+	if last := (*state.State()).GetLastEvent(); last != nil {
+		if setter, ok := any(placeholder).(es.LastEventSetter[R]); ok {
+			setter.SetLastEvent(&es.Event[R]{
+				EventId:      last.EventId,
+				PartitionKey: last.PartitionKey,
+				EventType:    last.EventType,
+				EventNo:      last.EventNo,
+				EventAt:      last.EventAt,
+			})
+		}
+	}
+	return a.storage.SaveSnapshot(pk, es.NewSealedState[S, R](placeholder, sealed))
+}
+
+func (a *SnapshotStorage[S, R]) GetSnapshot(pk es.PartitionKey) (*es.State[S, R], error) {
+	return a.unseal(pk)(a.storage.GetSnapshot(pk))
+}
+
+// unseal | 암호화된 snapshot 을 복호화한다. data key 가 지워졌으면 snapshot 이 없는 것으로 본다.
+func (a *SnapshotStorage[S, R]) unseal(pk es.PartitionKey) func(state *es.State[S, R], err error) (*es.State[S, R], error) {
+	return func(state *es.State[S, R], err error) (*es.State[S, R], error) {
+		if err != nil || state == nil || state.Sealed() == nil {
+			return state, err
+		}
+		plain, err := open(a.keys, pk, state.Sealed(), []byte(pk))
+		if errors.Is(err, es.ErrKeyShredded) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		unsealed := new(S)
+		if err = json.Unmarshal(plain, unsealed); err != nil {
+			return nil, err
+		}
+		return es.NewState[S, R](unsealed), nil
+	}
+}
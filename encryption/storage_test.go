@@ -0,0 +1,75 @@
+package encryption
+
+import (
+	es "eventsourcing"
+	"eventsourcing/estest"
+	"eventsourcing/example/currency"
+	"eventsourcing/memory"
+	"testing"
+)
+
+var testEventType = &es.EventType{Domain: "test", Name: "test", Version: "v1"}
+
+func TestEventStorage_Tamper(t *testing.T) {
+	if _, ok := NewEventStorage[string](memory.NewEventStorage[string](), memory.NewKeyStore()).(es.EventIdRangeStorage[string]); !ok {
+		t.Error("storage must keep EventIdRangeStorage")
+	}
+	raw := estest.NewTamperedEventStorage[string](memory.NewEventStorage[string]())
+	storage := NewEventStorage[string](raw, memory.NewKeyStore())
+
+	a, b := "a", "b"
+	first := es.NewEvent[string]("pk", testEventType, 1, &a)
+	second := es.NewEvent[string]("pk", testEventType, 2, &b)
+	_ = storage.AddEvent(first)
+	_ = storage.AddEvent(second)
+	if *first.Request != "a" || first.Encrypted != nil {
+		t.Errorf("AddEvent must not change the event. %s", first)
+	}
+
+	got, err := storage.GetEvent(second.EventId)
+	if err != nil || *got.Request != "b" {
+		t.Fatalf("GetEvent = %s, %v", got, err)
+	}
+
+	// 다른 event 의 암호문으로 바꾸면 복호화에 실패한다
+	stored, _ := raw.GetEvent(second.EventId)
+	tampered, _ := raw.GetEvent(first.EventId)
+	stored.Encrypted = tampered.Encrypted
+	raw.Tamper(stored)
+	if _, err = storage.GetEvent(second.EventId); err == nil {
+		t.Error("tampered event must fail to decrypt")
+	}
+}
+
+func TestSnapshotStorage_History(t *testing.T) {
+	keys := memory.NewKeyStore()
+	raw := memory.NewSnapshotHistoryStorage[currency.State, currency.Request](nil)
+	storage := NewSnapshotStorage[currency.State, currency.Request](raw, keys)
+	history, ok := storage.(es.StateSnapshotHistoryStorage[currency.State, currency.Request])
+	if !ok {
+		t.Fatal("storage must keep StateSnapshotHistoryStorage")
+	}
+
+	for no := 1; no <= 3; no++ {
+		state := currency.NewState("pk")
+		state.State().Amount = no * 10
+		state.State().LastEvent = es.NewEvent[currency.Request]("pk", &currency.AddAmountEvent, no, &currency.Request{Amount: 10})
+		if err := storage.SaveSnapshot("pk", state); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	infos, _ := history.GetSnapshotHistory("pk")
+	if len(infos) != 3 || infos[2].EventNo != 3 {
+		t.Fatalf("infos = %s", es.JsonString(infos))
+	}
+	state, err := history.GetSnapshotAtOrBefore("pk", 2)
+	if err != nil || state.State().Amount != 20 || state.State().LastEvent.Request.Amount != 10 {
+		t.Fatalf("snapshot at 2 = %v, %v", state, err)
+	}
+
+	_ = keys.DeleteKeys("pk")
+	if state, _ = history.GetSnapshotAtOrBefore("pk", 2); state != nil {
+		t.Errorf("shredded snapshot = %s", state)
+	}
+}
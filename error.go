@@ -42,6 +42,16 @@ import (
 //
 // [SnapshotStorageError]
 // - Snapshot Storage 의 에러가 발생하는 경우 사용하는 에러
+//
+// [Unauthorized]
+// - Authorizer 가 actor 의 Put 이나 조회를 허용하지 않은 경우 사용하는 에러
+// 권한 정책에 따른 거부이므로 다시 시도해도 같은 결과이고, 호출자에게 그대로 알려야 함.
+//
+// 에러 다루기
+// - errors.Is(err, ErrCommand) 처럼 Code 별 sentinel 로 에러 종류를 확인한다. (Code 가 같으면 같은 에러로 본다)
+// - errors.As(err, &esErr) 로 꺼낸 뒤, PartitionKey(), EventType(), EventNo() 로 어디서 발생한 에러인지 확인한다.
+// - errors.Is, errors.As 는 감싼 원인 에러(Unwrap)까지 확인한다.
+// - IsRetryable 로 다시 시도할만한 에러인지 판단한다.
 
 // Code | 이벤트 소싱에서 다루는 에러 케이스의 코드 정의
 type Code int
@@ -57,6 +67,21 @@ const (
 	DispenseEventNoError
 	EventStorageError
 	SnapshotStorageError
+	Unauthorized
+)
+
+// Code 별 sentinel, errors.Is 로 에러의 Code 를 확인할 때 사용한다.
+var (
+	ErrAlreadyLockedEvent   = newEventSourceError(AlreadyLockedEvent, nil, "already locked event")
+	ErrAlreadyUnlockedEvent = newEventSourceError(AlreadyUnlockedEvent, nil, "already unlocked event")
+	ErrNoHasLock            = newEventSourceError(NoHasLockError, nil, "no has lock")
+	ErrNoHasCommand         = newEventSourceError(NoHasCommand, nil, "no has command")
+	ErrCommand              = newEventSourceError(CommandError, nil, "occur error command")
+	ErrValidate             = newEventSourceError(ValidateError, nil, "occur error validate")
+	ErrDispenseEventNo      = newEventSourceError(DispenseEventNoError, nil, "occur error dispense eventNo")
+	ErrEventStorage         = newEventSourceError(EventStorageError, nil, "occur error event storage")
+	ErrSnapshotStorage      = newEventSourceError(SnapshotStorageError, nil, "occur error snapshot storage")
+	ErrUnauthorized         = newEventSourceError(Unauthorized, nil, "unauthorized")
 )
 
 // EventSourceError | 이벤트 소싱에서 다루는 에러를 wrapping 한 구조체
@@ -65,6 +90,10 @@ type EventSourceError struct {
 	err    error  // nullable, 에러 정보
 	format string // string 변환 시 사용할 포맷
 	args   []any  // string 변환 시 사용할 args
+
+	pk        PartitionKey // nullable, 에러가 발생한 partition key
+	eventType *EventType   // nullable, 에러가 발생한 event type
+	eventNo   int          // nullable, 에러가 발생한 event 번호
 }
 
 func newEventSourceError(code Code, err error, format string, args ...any) *EventSourceError {
@@ -76,6 +105,38 @@ func newEventSourceError(code Code, err error, format string, args ...any) *Even
 	}
 }
 
+// at | 에러가 발생한 위치를 기록
+func (e *EventSourceError) at(pk PartitionKey, et *EventType, eventNo int) *EventSourceError {
+	e.pk, e.eventType, e.eventNo = pk, et, eventNo
+	return e
+}
+
+// PartitionKey | 에러가 발생한 partition key, 알 수 없으면 빈 값
+func (e *EventSourceError) PartitionKey() PartitionKey {
+	return e.pk
+}
+
+// EventType | 에러가 발생한 event type, 알 수 없으면 nil
+func (e *EventSourceError) EventType() *EventType {
+	return e.eventType
+}
+
+// EventNo | 에러가 발생한 event 번호, 알 수 없으면 0
+func (e *EventSourceError) EventNo() int {
+	return e.eventNo
+}
+
+// Unwrap | 원인 에러
+func (e *EventSourceError) Unwrap() error {
+	return e.err
+}
+
+// Is | Code 가 같은 EventSourceError 라면 같은 에러로 본다.
+func (e *EventSourceError) Is(target error) bool {
+	t, ok := target.(*EventSourceError)
+	return ok && t.Code == e.Code
+}
+
 // error interface 를 구현
 func (e *EventSourceError) Error() string {
 	var returnError error
@@ -98,27 +159,27 @@ func (e *EventSourceError) Error() string {
 }
 
 func NewLockedEventError(err error, pk PartitionKey, et *EventType) error {
-	return newEventSourceError(AlreadyLockedEvent, err, "already locked. pk(%s), eventType(%s)", pk, et.String())
+	return newEventSourceError(AlreadyLockedEvent, err, "already locked. pk(%s), eventType(%s)", pk, et.String()).at(pk, et, 0)
 }
 
 func NewUnlockedEventError(err error, pk PartitionKey, et *EventType) error {
-	return newEventSourceError(AlreadyUnlockedEvent, err, "already unlocked. pk(%s), eventType(%s)", pk, et.String())
+	return newEventSourceError(AlreadyUnlockedEvent, err, "already unlocked. pk(%s), eventType(%s)", pk, et.String()).at(pk, et, 0)
 }
 
 func NewNoHasCommandError(pk PartitionKey, et *EventType) error {
-	return newEventSourceError(NoHasCommand, nil, "no has command. pk(%s), eventType(%s)", pk, et.String())
+	return newEventSourceError(NoHasCommand, nil, "no has command. pk(%s), eventType(%s)", pk, et.String()).at(pk, et, 0)
 }
 
 func NewCommandError[R any](err error, pk PartitionKey, e *Event[R]) error {
-	return newEventSourceError(CommandError, err, "occur error command. pk(%s), event(%s)", pk, e.String())
+	return newEventSourceError(CommandError, err, "occur error command. pk(%s), event(%s), eventNo(%d)", pk, e.EventType.String(), e.EventNo).at(pk, e.EventType, e.EventNo)
 }
 
 func NewValidateError[S CommonState[R], R any](err error, pk PartitionKey, s *State[S, R]) error {
-	return newEventSourceError(ValidateError, err, "occur error command. pk(%s), state(%s)", pk, s.String())
+	return newEventSourceError(ValidateError, err, "occur error command. pk(%s), state(%s)", pk, JsonString(s.State())).at(pk, nil, 0)
 }
 
 func NewDispenseEventNoError(err error, pk PartitionKey) error {
-	return newEventSourceError(DispenseEventNoError, err, "occur error dispense eventNo. pk(%s)", pk)
+	return newEventSourceError(DispenseEventNoError, err, "occur error dispense eventNo. pk(%s)", pk).at(pk, nil, 0)
 }
 
 func NewEventStorageError(err error) error {
@@ -128,3 +189,57 @@ func NewEventStorageError(err error) error {
 func NewSnapshotStorageError(err error) error {
 	return newEventSourceError(SnapshotStorageError, err, "")
 }
+
+// NewUnauthorizedError | actor 의 요청을 허용하지 않음, et 가 nil 이면 조회 요청
+func NewUnauthorizedError(err error, actor Actor, pk PartitionKey, et *EventType) error {
+	if et == nil {
+		return newEventSourceError(Unauthorized, err, "unauthorized read. actor(%s), pk(%s)", actor, pk).at(pk, nil, 0)
+	}
+	return newEventSourceError(Unauthorized, err, "unauthorized put. actor(%s), pk(%s), eventType(%s)", actor, pk, et.String()).at(pk, et, 0)
+}
+
+// Retryable | 원인 에러가 다시 시도할만한 에러인지 직접 알려줄 때 구현하는 인터페이스 (선택)
+type Retryable interface {
+	Retryable() bool
+}
+
+// permanentError | 다시 시도해도 같은 결과인 에러, Retryable 은 false
+type permanentError struct {
+	error
+}
+
+func (e *permanentError) Retryable() bool { return false }
+func (e *permanentError) Unwrap() error   { return e.error }
+
+// Permanent | storage 가 돌려주는 에러 중 다시 시도해도 같은 결과인 에러(충돌, 복호화 실패 등)를 표시한다.
+// retry 는 이 에러를 다시 시도하지 않고, circuit breaker 의 실패로 세지 않는다.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{error: err}
+}
+
+// IsRetryable | 다시 시도하면 성공할 수도 있는 에러인지 판단한다.
+// - 에러 체인에 Retryable 을 구현한 에러가 있으면 그 판단을 따른다.
+// - 아니면 storage 에서 발생한 에러(DispenseEventNoError, EventStorageError, SnapshotStorageError)만 다시 시도할만한 에러로 본다.
+// - Process, Validate 의 에러처럼 코드로 결정되는 에러는 다시 시도해도 같은 결과이므로 영구적인 에러로 본다.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var r Retryable
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+	var e *EventSourceError
+	if !errors.As(err, &e) {
+		return false
+	}
+	switch e.Code {
+	case DispenseEventNoError, EventStorageError, SnapshotStorageError:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,55 @@
+package eventsourcing
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type retryableError bool
+
+func (e retryableError) Error() string   { return "retryable error" }
+func (e retryableError) Retryable() bool { return bool(e) }
+
+func TestEventSourceError_IsAs(t *testing.T) {
+	cause := errors.New("cause")
+	e := NewEvent[int]("pk", &processTestEventType, 7, nil)
+	err := fmt.Errorf("wrapped: %w", NewCommandError(cause, "pk", e))
+
+	if !errors.Is(err, ErrCommand) || errors.Is(err, ErrValidate) {
+		t.Errorf("errors.Is by Code is wrong. err(%v)", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("errors.Is must match the cause")
+	}
+
+	var esErr *EventSourceError
+	if !errors.As(err, &esErr) {
+		t.Fatal("errors.As must find EventSourceError")
+	}
+	if esErr.PartitionKey() != "pk" || esErr.EventNo() != 7 || esErr.EventType().String() != processTestEventType.String() {
+		t.Errorf("pk(%s), eventType(%v), eventNo(%d)", esErr.PartitionKey(), esErr.EventType(), esErr.EventNo())
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("unknown"), false},
+		{NewEventStorageError(errors.New("timeout")), true},
+		{NewSnapshotStorageError(errors.New("timeout")), true},
+		{NewDispenseEventNoError(errors.New("timeout"), "pk"), true},
+		{NewNoHasCommandError("pk", &processTestEventType), false},
+		{NewCommandError(errors.New("bug"), "pk", NewEvent[int]("pk", &processTestEventType, 1, nil)), false},
+		{NewEventStorageError(retryableError(false)), false}, // 원인 에러의 판단을 따름
+		{fmt.Errorf("wrapped: %w", retryableError(true)), true},
+	}
+	for i, c := range cases {
+		if got := IsRetryable(c.err); got != c.want {
+			t.Errorf("cases[%d] IsRetryable(%v) = %v, want %v", i, c.err, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Package estest | Given/When/Then 형태로 도메인의 Processor, Validator 를 검증하는 테스트 하네스
+//
+// 도메인 테스트를 위해 Event 를 직접 만들고 State 를 손으로 이어붙이지 않아도 되도록,
+// in-memory storage 를 사용하는 실제 Manager 위에서 시나리오를 실행한다.
+//
+//	estest.NewScenario[currency.State, currency.Request](t, currency.Rule, currency.Processor, currency.Validator).
+//		Given(&currency.CreateAmountStateEvent, nil).
+//		Given(&currency.AddAmountEvent, &currency.Request{Amount: 100}).
+//		When(&currency.MinusAmountEvent, &currency.Request{Amount: 30}).
+//		ThenState(&currency.State{PartitionKey: "pk", Amount: 70}, "lastEvent")
+package estest
+
+import (
+	es "eventsourcing"
+	"eventsourcing/manager"
+	"eventsourcing/memory"
+	"strings"
+	"testing"
+	"time"
+)
+
+// DefaultPartitionKey | 시나리오에서 별도로 지정하지 않으면 사용하는 PartitionKey
+const DefaultPartitionKey = es.PartitionKey("estest")
+
+// StartTime | 시나리오의 FakeClock 이 시작하는 시간
+var StartTime = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Scenario | 하나의 partition 에 대해 Given/When/Then 을 실행하는 시나리오
+type Scenario[S es.CommonState[R], R any] struct {
+	t       testing.TB
+	pk      es.PartitionKey
+	clock   *es.FakeClock
+	manager manager.Manager[S, R]
+	when    *es.EventType // When 으로 제안된 EventType, nil 이면 When 이 호출되지 않은 상태
+	whenErr error         // When 처리 중 발생한 에러 (validate 거절 포함)
+}
+
+// NewScenario | 도메인의 Rule, Processor, Validator 와 in-memory storage 로 Manager 를 만들어 시나리오를 시작한다.
+// 시간은 StartTime 에서 멈춰있는 FakeClock, EventId 는 "estest-" 로 시작하는 SequenceIdGenerator 를 사용하므로 실행할 때마다 같은 Event 가 만들어진다.
+// opts 는 기본 구성 뒤에 적용된다.
+func NewScenario[S es.CommonState[R], R any](
+	t testing.TB,
+	rule *es.Rule,
+	p *es.Processor[S, R],
+	v *es.Validator[S, R],
+	opts ...manager.Option[S, R],
+) *Scenario[S, R] {
+	clock := es.NewFakeClock(StartTime)
+	opts = append([]manager.Option[S, R]{
+		manager.WithClock[S, R](clock),
+		manager.WithIdGenerator[S, R](es.NewSequenceIdGenerator("estest-")),
+	}, opts...)
+	return &Scenario[S, R]{
+		t:     t,
+		pk:    DefaultPartitionKey,
+		clock: clock,
+		manager: manager.NewBaseManager[S, R](
+			rule,
+			p,
+			v,
+			memory.NewEventStorage[R](),
+			memory.NewSnapshotStorage[S, R](),
+			opts...,
+		),
+	}
+}
+
+// WithPartitionKey | 시나리오에서 사용할 PartitionKey 를 지정한다. Given 이전에 호출해야 한다.
+func (s *Scenario[S, R]) WithPartitionKey(pk es.PartitionKey) *Scenario[S, R] {
+	s.pk = pk
+	return s
+}
+
+// Clock | 시나리오가 사용하는 FakeClock
+func (s *Scenario[S, R]) Clock() *es.FakeClock {
+	return s.clock
+}
+
+// Advance | 다음 Event 전에 시간을 d 만큼 흐르게 한다.
+func (s *Scenario[S, R]) Advance(d time.Duration) *Scenario[S, R] {
+	s.clock.Advance(d)
+	return s
+}
+
+// Manager | 시나리오가 사용하는 Manager, 하네스가 제공하지 않는 검증이 필요할 때 사용한다.
+func (s *Scenario[S, R]) Manager() manager.Manager[S, R] {
+	return s.manager
+}
+
+// Given | 이미 일어난 Event 를 쌓는다. Validate 없이 저장하고 바로 적용한다.
+func (s *Scenario[S, R]) Given(et *es.EventType, req *R, opts ...es.PutOption) *Scenario[S, R] {
+	s.t.Helper()
+	if s.when != nil {
+		s.t.Fatalf("given(%s) must be called before when(%s)", et.String(), s.when.String())
+	}
+	if _, err := s.manager.Put(s.pk, et, req, opts...); err != nil {
+		s.t.Fatalf("given(%s) put failed. %s", et.String(), err)
+	}
+	if err := s.manager.ApplyEvents(s.pk); err != nil {
+		s.t.Fatalf("given(%s) apply failed. %s", et.String(), err)
+	}
+	return s
+}
+
+// When | 새 Event 를 제안한다. Validate 를 통과하면 저장하고 적용하며, 실패하면 Then 에서 검사할 수 있도록 에러를 보관한다.
+func (s *Scenario[S, R]) When(et *es.EventType, req *R, opts ...es.PutOption) *Scenario[S, R] {
+	s.t.Helper()
+	if s.when != nil {
+		s.t.Fatalf("when(%s) is already called", s.when.String())
+	}
+	s.when = et
+
+	if s.whenErr = s.manager.Validate(s.pk, et); s.whenErr != nil {
+		return s
+	}
+	if _, s.whenErr = s.manager.Put(s.pk, et, req, opts...); s.whenErr != nil {
+		return s
+	}
+	s.whenErr = s.manager.ApplyEvents(s.pk)
+	return s
+}
+
+// Then | When 이 성공했는지 확인하고, 최신 State 를 assert 함수로 넘긴다.
+func (s *Scenario[S, R]) Then(assert func(t testing.TB, state *S)) *Scenario[S, R] {
+	s.t.Helper()
+	state := s.latestState()
+	if state == nil {
+		s.t.Fatalf("state is nil. pk(%s)", s.pk)
+		return s
+	}
+	assert(s.t, state.State())
+	return s
+}
+
+// ThenState | When 이 성공했는지 확인하고, 최신 State 가 expected 와 같은지 field 단위로 비교한다.
+// ignores 는 비교하지 않을 json 경로이며, ex) "lastEvent" 처럼 검증 대상이 아닌 field 에 사용한다.
+func (s *Scenario[S, R]) ThenState(expected *S, ignores ...string) *Scenario[S, R] {
+	s.t.Helper()
+	state := s.latestState()
+
+	var actual *S
+	if state != nil {
+		actual = state.State()
+	}
+	diffs, err := es.DiffJson(expected, actual, ignores...)
+	if err != nil {
+		s.t.Fatalf("diff state failed. %s", err)
+	}
+	if len(diffs) > 0 {
+		lines := make([]string, len(diffs))
+		for i, d := range diffs {
+			lines[i] = "\t" + d.String()
+		}
+		s.t.Errorf("state mismatch. pk(%s)\n%s", s.pk, strings.Join(lines, "\n"))
+	}
+	return s
+}
+
+// ThenRejected | When 의 Event 가 거절되었는지 확인한다. contains 가 비어있지 않으면 에러 메세지에 포함되어야 한다.
+func (s *Scenario[S, R]) ThenRejected(contains string) *Scenario[S, R] {
+	s.t.Helper()
+	s.requireWhen()
+	if s.whenErr == nil {
+		s.t.Errorf("expected when(%s) to be rejected, but accepted", s.when.String())
+		return s
+	}
+	if contains != "" && !strings.Contains(s.whenErr.Error(), contains) {
+		s.t.Errorf("rejection of when(%s) does not contain %q. %s", s.when.String(), contains, s.whenErr)
+	}
+	return s
+}
+
+func (s *Scenario[S, R]) requireWhen() {
+	s.t.Helper()
+	if s.when == nil {
+		s.t.Fatalf("when is not called")
+	}
+}
+
+func (s *Scenario[S, R]) latestState() *es.State[S, R] {
+	s.t.Helper()
+	s.requireWhen()
+	if s.whenErr != nil {
+		s.t.Fatalf("when(%s) failed. %s", s.when.String(), s.whenErr)
+	}
+	state, err := s.manager.GetLatestState(s.pk)
+	if err != nil {
+		s.t.Fatalf("get latest state failed. pk(%s) - %s", s.pk, err)
+	}
+	return state
+}
@@ -0,0 +1,65 @@
+package estest
+
+import (
+	es "eventsourcing"
+	"sync"
+)
+
+// TamperedEventStorage | 저장된 event 대신 바꿔치기한 event 를 돌려주는 EventStorage, 위변조를 찾아내는지 테스트할 때 사용
+// EventStorage 는 저장된 event 를 고칠 수 없으므로, 저장소를 직접 고친 것처럼 조회 결과만 바꾼다.
+type TamperedEventStorage[R any] struct {
+	es.EventStorage[R]
+	locker sync.RWMutex
+	forged map[es.EventId]*es.Event[R]
+}
+
+// NewTamperedEventStorage | storage 를 감싸서 Tamper 한 event 를 조회 결과에 섞는다.
+func NewTamperedEventStorage[R any](storage es.EventStorage[R]) *TamperedEventStorage[R] {
+	return &TamperedEventStorage[R]{EventStorage: storage, forged: make(map[es.EventId]*es.Event[R])}
+}
+
+// Tamper | 이후 e.EventId 의 event 를 조회하면 e 를 돌려준다.
+func (a *TamperedEventStorage[R]) Tamper(e *es.Event[R]) {
+	a.locker.Lock()
+	defer a.locker.Unlock()
+	forged := *e
+	a.forged[e.EventId] = &forged
+}
+
+func (a *TamperedEventStorage[R]) swap(e *es.Event[R]) *es.Event[R] {
+	if e == nil {
+		return nil
+	}
+	a.locker.RLock()
+	defer a.locker.RUnlock()
+	if forged, ok := a.forged[e.EventId]; ok {
+		copied := *forged
+		return &copied
+	}
+	return e
+}
+
+func (a *TamperedEventStorage[R]) swapAll(events []*es.Event[R], err error) ([]*es.Event[R], error) {
+	for i, e := range events {
+		events[i] = a.swap(e)
+	}
+	return events, err
+}
+
+func (a *TamperedEventStorage[R]) GetEvent(id es.EventId) (*es.Event[R], error) {
+	e, err := a.EventStorage.GetEvent(id)
+	return a.swap(e), err
+}
+
+func (a *TamperedEventStorage[R]) GetEvents(pk es.PartitionKey) ([]*es.Event[R], error) {
+	return a.swapAll(a.EventStorage.GetEvents(pk))
+}
+
+func (a *TamperedEventStorage[R]) GetEventsAfterEventNo(pk es.PartitionKey, eno int) ([]*es.Event[R], error) {
+	return a.swapAll(a.EventStorage.GetEventsAfterEventNo(pk, eno))
+}
+
+func (a *TamperedEventStorage[R]) GetLastEvent(pk es.PartitionKey) (*es.Event[R], error) {
+	e, err := a.EventStorage.GetLastEvent(pk)
+	return a.swap(e), err
+}
@@ -2,7 +2,6 @@ package eventsourcing
 
 import (
 	"fmt"
-	"github.com/rs/xid"
 	"time"
 )
 
@@ -11,18 +10,39 @@ type Event[R any] struct {
 	EventId      EventId      `json:"eventId"`
 	PartitionKey PartitionKey `json:"partitionKey"`
 	*EventType
-	EventNo int       `json:"eventNo"`
-	EventAt time.Time `json:"eventAt"`
-	Request *R        `json:"request"` // Domain 마다 fit 하게 만들어진 구조체를 넣는다
+	EventNo        int               `json:"eventNo"`
+	EventAt        time.Time         `json:"eventAt"`
+	Request        *R                `json:"request"`                  // Domain 마다 fit 하게 만들어진 구조체를 넣는다
+	CorrelationId  string            `json:"correlationId,omitempty"`  // 같은 흐름으로 이어지는 event 들이 공유하는 id, 흐름의 첫 event 라면 자신의 EventId
+	CausationId    EventId           `json:"causationId,omitempty"`    // 이 event 를 일으킨 event 의 id
+	Actor          Actor             `json:"actor,omitempty"`          // event 를 요청한 주체
+	Metadata       map[string]string `json:"metadata,omitempty"`       // 그 외 custom header
+	IdempotencyKey string            `json:"idempotencyKey,omitempty"` // 클라이언트가 지정한 멱등키, 같은 키로 다시 Put 하면 처음 저장된 event 를 돌려준다
+	Encrypted      *EncryptedPayload `json:"encrypted,omitempty"`      // 암호화해서 저장한 Request, 저장소에는 Request 가 nil 이고 복호화해서 읽으면 둘 다 있다
+	Hash           string            `json:"hash,omitempty"`           // 이 event 의 hash, HashEvent 참고
+	PrevHash       string            `json:"prevHash,omitempty"`       // partition 의 이전 event 의 Hash, 첫 event 는 빈 값
+	KeyId          string            `json:"keyId,omitempty"`          // 서명에 사용한 key 의 id
+	Signature      []byte            `json:"signature,omitempty"`      // 저장할 때 남긴 서명, EventSigningBytes 참고
 }
 
+// Redacted | Event 의 json 문자열, Request 의 `es:"redact"` field 는 가린다. String 은 embed 한 EventType 의 이름이다.
+func (e *Event[R]) Redacted() string {
+	return JsonString(e)
+}
+
+// NewEvent | 기본 IdGenerator 와 Clock 으로 Event 를 생성
 func NewEvent[R any](pk PartitionKey, eventType *EventType, no int, request *R) *Event[R] {
+	return NewEventWith[R](DefaultIdGenerator, DefaultClock, pk, eventType, no, request)
+}
+
+// NewEventWith | 주입받은 IdGenerator 와 Clock 으로 Event 를 생성
+func NewEventWith[R any](gen IdGenerator, clock Clock, pk PartitionKey, eventType *EventType, no int, request *R) *Event[R] {
 	return &Event[R]{
-		EventId:      EventId(xid.New().String()),
+		EventId:      gen.NewId(),
 		PartitionKey: pk,
 		EventType:    eventType,
 		EventNo:      no,
-		EventAt:      time.Now().UTC(),
+		EventAt:      clock.Now(),
 		Request:      request,
 	}
 }
@@ -35,10 +55,23 @@ type EventType struct {
 	NeedLock bool         `json:"needLock"` // Event 검사에 lock 이 필요한지 여부
 }
 
+// String | Domain, Name, Version 을 '_' 로 이은 이름, 이름에 '_' 가 들어가면 다른 EventType 과 겹칠 수 있으므로 map 의 key 로는 Key 를 사용한다.
 func (e *EventType) String() string {
 	return fmt.Sprintf("%s_%s_%s", e.Domain, e.Name, e.Version)
 }
 
+// Key | EventType 을 구분하는 key
+func (e *EventType) Key() EventTypeKey {
+	return EventTypeKey{Domain: e.Domain, Name: e.Name, Version: e.Version}
+}
+
+// EventTypeKey | EventType 을 구분하는 값, NeedLock 처럼 종류와 관계없는 속성은 뺀다.
+type EventTypeKey struct {
+	Domain  Domain
+	Name    EventName
+	Version EventVersion
+}
+
 // EventId | Event 의 고유 아이디, sorted 해야 한다. Event 는 이 type 을 key 로 삼아야 함
 type EventId string
 
@@ -53,3 +86,6 @@ type EventName string
 
 // EventVersion | Event 의 버전, Domain, EventName 이 같은데 실제 Process 가 달라야 한다면 Version 을 높인 Event를 새로 정의한다.
 type EventVersion string
+
+// Actor | Event 를 요청한 주체, 사용자나 서비스를 구분할 수 있는 값을 넣는다.
+type Actor string
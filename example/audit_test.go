@@ -0,0 +1,99 @@
+package example
+
+import (
+	"context"
+	"encoding/json"
+	es "eventsourcing"
+	"eventsourcing/example/currency"
+	"eventsourcing/example/storage"
+	"eventsourcing/manager"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestAuditor(t *testing.T) {
+	eventStorage := storage.NewCurrencyEventStorage()
+	snapshotStorage := storage.NewCurrencySnapshotStorage()
+	m := manager.NewBaseManager[currency.State, currency.Request](
+		currency.Rule,
+		currency.Processor,
+		currency.Validator,
+		eventStorage,
+		snapshotStorage,
+	)
+
+	pks := make([]es.PartitionKey, 5)
+	for i := range pks {
+		pks[i] = es.PartitionKey(fmt.Sprintf("pk_%d", i))
+		if _, err := m.Put(pks[i], &currency.CreateAmountStateEvent, nil); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Put(pks[i], &currency.AddAmountEvent, &currency.Request{Amount: 100}); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.ApplyEvents(pks[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// pk_1 은 snapshot 이 망가지고, 이후 event 가 더 쌓임
+	snapshot, _ := m.GetStateSnapshot(pks[1])
+	snapshot.State().Amount = 999
+	_ = snapshotStorage.SaveSnapshot(pks[1], snapshot)
+	if _, err := m.Put(pks[1], &currency.AddAmountEvent, &currency.Request{Amount: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	auditor := manager.NewAuditor[currency.State, currency.Request](m, eventStorage, manager.AuditConfig{})
+	report, err := auditor.Audit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Audited != 5 || report.Consistent != 4 || report.Inconsistent != 1 || report.Repaired != 0 {
+		t.Fatalf("report = %s", es.JsonString(report))
+	}
+	result := report.Results[0]
+	if result.PartitionKey != pks[1] || len(result.Diffs) != 1 || result.Diffs[0].Path != "amount" {
+		t.Fatalf("result = %s", es.JsonString(result))
+	}
+	t.Log(result.Diffs[0])
+
+	// 복구
+	auditor = manager.NewAuditor[currency.State, currency.Request](m, eventStorage, manager.AuditConfig{Repair: true})
+	if result = auditor.AuditPartition(pks[1]); !result.Repaired {
+		t.Fatalf("result = %s", es.JsonString(result))
+	}
+	if result = auditor.AuditPartition(pks[1]); !result.Consistent() {
+		t.Errorf("after repair = %s", es.JsonString(result))
+	}
+	snapshot, _ = m.GetStateSnapshot(pks[1])
+	if snapshot.State().Amount != 101 {
+		t.Errorf("repaired amount = %d, want 101", snapshot.State().Amount)
+	}
+
+	// sampling
+	auditor = manager.NewAuditor[currency.State, currency.Request](m, eventStorage, manager.AuditConfig{
+		SampleRate: 0.5,
+		Random:     rand.New(rand.NewSource(1)),
+	})
+	report, err = auditor.Audit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Audited == 0 || report.Audited == 5 {
+		t.Errorf("sampled audited = %d", report.Audited)
+	}
+
+	// 실패한 partition 은 json 에도 에러 메시지를 남김
+	if _, err := m.Put("pk_broken", &es.EventType{Domain: "currency", Name: "unknown", Version: "v1"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	result = auditor.AuditPartition("pk_broken")
+	var marshaled struct {
+		Error string `json:"error"`
+	}
+	body, _ := json.Marshal(result)
+	if err := json.Unmarshal(body, &marshaled); err != nil || result.Err == nil || marshaled.Error != result.Err.Error() {
+		t.Errorf("broken result = %s, %v", es.JsonString(result), err)
+	}
+}
@@ -0,0 +1,93 @@
+package example
+
+import (
+	"errors"
+	es "eventsourcing"
+	"eventsourcing/example/currency"
+	"eventsourcing/example/storage"
+	"eventsourcing/manager"
+	"testing"
+)
+
+// limitAuthorizer | request 를 보고 판단하는 Authorizer, 큰 금액은 허용하지 않는다
+type limitAuthorizer struct {
+	*es.RoleTable[currency.Request]
+	limit int
+}
+
+func (a *limitAuthorizer) AuthorizePut(actor es.Actor, pk es.PartitionKey, et *es.EventType, req *currency.Request) error {
+	if req != nil && req.Amount > a.limit {
+		return errors.New("amount is over the limit")
+	}
+	return a.RoleTable.AuthorizePut(actor, pk, et, req)
+}
+
+func TestAuthorizedManager(t *testing.T) {
+	roles := es.NewRoleTable[currency.Request]()
+	roles.Grant("teller",
+		es.Permission{Action: es.ActionRead, PartitionKeyPrefix: "branch-a/"},
+		es.Permission{Action: es.ActionPut, Domain: "currency", EventName: "create_currency_state", PartitionKeyPrefix: "branch-a/"},
+		es.Permission{Action: es.ActionPut, Domain: "currency", EventName: "add_amount", PartitionKeyPrefix: "branch-a/"},
+	)
+	roles.Grant("auditor", es.Permission{Action: es.ActionRead})
+	roles.Assign("alice", "teller")
+	roles.Assign("bob", "auditor")
+	authorizer := &limitAuthorizer{RoleTable: roles, limit: 1000}
+
+	base := manager.NewBaseManager[currency.State, currency.Request](
+		nil,
+		currency.Processor,
+		currency.Validator,
+		storage.NewCurrencyEventStorage(),
+		storage.NewCurrencySnapshotStorage(),
+	)
+	alice := manager.NewAuthorizedManager[currency.State, currency.Request](base, authorizer, "alice")
+	bob := manager.NewAuthorizedManager[currency.State, currency.Request](base, authorizer, "bob")
+	pk := es.PartitionKey("branch-a/1")
+
+	// 허용한 EventType 은 저장되고 actor 가 기록된다
+	if _, err := alice.Put(pk, &currency.CreateAmountStateEvent, nil); err != nil {
+		t.Fatal(err)
+	}
+	added, err := alice.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 100}, es.WithActor("mallory"))
+	if err != nil || added.Actor != "alice" {
+		t.Fatalf("added = %v, %v", added, err)
+	}
+
+	// 허용하지 않은 EventType, partition, request
+	put := func(m manager.Manager[currency.State, currency.Request], pk es.PartitionKey, et *es.EventType, amount int) error {
+		_, err := m.Put(pk, et, &currency.Request{Amount: amount})
+		return err
+	}
+	_, readErr := alice.GetLatestState("branch-b/1")
+	denied := []struct {
+		name string
+		err  error
+	}{
+		{"event type", alice.Validate(pk, &currency.BurnEvent)},
+		{"partition", put(alice, "branch-b/1", &currency.AddAmountEvent, 1)},
+		{"request", put(alice, pk, &currency.AddAmountEvent, 5000)},
+		{"read only", put(bob, pk, &currency.AddAmountEvent, 1)},
+		{"read", readErr},
+	}
+	for _, d := range denied {
+		var esErr *es.EventSourceError
+		if !errors.Is(d.err, es.ErrUnauthorized) || !errors.As(d.err, &esErr) || esErr.Code != es.Unauthorized {
+			t.Errorf("%s err = %v", d.name, d.err)
+		}
+		if es.IsRetryable(d.err) {
+			t.Errorf("%s err must not be retryable", d.name)
+		}
+	}
+
+	// 조회 권한
+	state, err := bob.GetLatestState(pk)
+	if err != nil || state.State().Amount != 100 {
+		t.Fatalf("state = %v, %v", state, err)
+	}
+
+	roles.Unassign("alice", "teller")
+	if _, err = alice.GetEvents(pk, 0); !errors.Is(err, es.ErrUnauthorized) {
+		t.Errorf("unassigned err = %v", err)
+	}
+}
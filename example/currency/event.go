@@ -17,6 +17,17 @@ var (
 	BurnEvent              = es.EventType{Domain: "currency", Name: "burn", Version: "v1", NeedLock: true}
 )
 
+// Registry | 도메인에서 다루는 Event 목록, 새 Event 를 정의하면 여기에 등록한다
+var Registry = es.NewEventTypeRegistry().MustRegister(
+	&CreateAmountStateEvent,
+	&AddAmountEvent,
+	&MinusAmountEvent,
+	&ChangeStatusEvent,
+	&ChangeValueEvent,
+	&ChangeValueV2Event,
+	&BurnEvent,
+)
+
 type Request struct {
 	Amount int
 	Status *Status
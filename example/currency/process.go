@@ -1,6 +1,7 @@
 package currency
 
 import (
+	"errors"
 	es "eventsourcing"
 )
 
@@ -8,6 +9,10 @@ import (
 State 수정/변경 하는 Process 를 구현한다.
 */
 
+var (
+	ErrEmptyRequest = errors.New("request is empty")
+)
+
 var (
 	Processor *es.Processor[State, Request]
 	_         es.Process[State, Request] = CreateCurrencyState
@@ -30,52 +35,65 @@ func init() {
 	Processor.SetProcess(ChangeValueEvent, ChangeValue)
 	Processor.SetProcess(ChangeValueV2Event, ChangeValueV2) // V2 의 Cmd 를 따로 매핑
 	Processor.SetProcess(BurnEvent, Burn)
+
+	// 등록한 모든 Event 가 Process 와 매핑되었는지 검사
+	if err := es.CheckProcessor[State, Request](Registry, Processor); err != nil {
+		panic(err)
+	}
 }
 
-func CreateCurrencyState(s *es.State[State, Request], e *es.Event[Request]) *es.State[State, Request] {
+func CreateCurrencyState(s *es.State[State, Request], e *es.Event[Request]) (*es.State[State, Request], error) {
 	s = es.NewState[State, Request](&State{
 		PartitionKey: e.PartitionKey,
 		Amount:       0,
 		Status:       NOTHING,
 		Value:        nil,
-		LastEvent:    e,
 	})
-	return s
+	return s, nil
 }
 
-func AddAmount(s *es.State[State, Request], e *es.Event[Request]) *es.State[State, Request] {
+func AddAmount(s *es.State[State, Request], e *es.Event[Request]) (*es.State[State, Request], error) {
+	if e.Request == nil {
+		return nil, ErrEmptyRequest
+	}
 	s.State().Amount = s.State().Amount + e.Request.Amount
-	s.State().LastEvent = e
-	return s
+	return s, nil
 }
 
-func MinusAmount(s *es.State[State, Request], e *es.Event[Request]) *es.State[State, Request] {
+func MinusAmount(s *es.State[State, Request], e *es.Event[Request]) (*es.State[State, Request], error) {
+	if e.Request == nil {
+		return nil, ErrEmptyRequest
+	}
 	s.State().Amount = s.State().Amount - e.Request.Amount
-	s.State().LastEvent = e
-	return s
+	return s, nil
 }
 
-func ChangeStatus(s *es.State[State, Request], e *es.Event[Request]) *es.State[State, Request] {
+func ChangeStatus(s *es.State[State, Request], e *es.Event[Request]) (*es.State[State, Request], error) {
+	if e.Request == nil || e.Request.Status == nil {
+		return nil, ErrEmptyRequest
+	}
 	s.State().Status = *e.Request.Status
-	s.State().LastEvent = e
-	return s
+	return s, nil
 }
 
-func ChangeValue(s *es.State[State, Request], e *es.Event[Request]) *es.State[State, Request] {
+func ChangeValue(s *es.State[State, Request], e *es.Event[Request]) (*es.State[State, Request], error) {
+	if e.Request == nil {
+		return nil, ErrEmptyRequest
+	}
 	s.State().Value = e.Request.Value
-	s.State().LastEvent = e
-	return s
+	return s, nil
 }
 
-func ChangeValueV2(s *es.State[State, Request], e *es.Event[Request]) *es.State[State, Request] {
+func ChangeValueV2(s *es.State[State, Request], e *es.Event[Request]) (*es.State[State, Request], error) {
+	if e.Request == nil {
+		return nil, ErrEmptyRequest
+	}
 	s.State().Amount = s.State().Amount + e.Request.Amount
 	s.State().Value = e.Request.Value
-	s.State().LastEvent = e
-	return s
+	return s, nil
 }
 
-func Burn(s *es.State[State, Request], e *es.Event[Request]) *es.State[State, Request] {
+func Burn(s *es.State[State, Request], e *es.Event[Request]) (*es.State[State, Request], error) {
 	s.State().Status = BURNED
-	s.State().LastEvent = e
-	return s
+	return s, nil
 }
@@ -9,7 +9,9 @@ Event Sourcing 으로 다루는 도메인의 State 를 여기에 구현한다.
 */
 
 var (
-	_ es.CommonState[Request] = State{}
+	_ es.CommonState[Request]     = State{}
+	_ es.Cloner[State]            = State{}
+	_ es.LastEventSetter[Request] = &State{}
 )
 
 type Status int
@@ -50,3 +52,17 @@ func (e State) GetLastEvent() *es.Event[Request] {
 func (e State) String() string {
 	return es.JsonString(e)
 }
+
+// SetLastEvent | Processor 가 Process 수행 후 적용한 이벤트를 기록한다.
+func (e *State) SetLastEvent(event *es.Event[Request]) {
+	e.LastEvent = event
+}
+
+// Clone | pointer field 를 복사한 State, LastEvent 는 저장된 뒤 바뀌지 않으므로 공유한다.
+func (e State) Clone() *State {
+	if e.Value != nil {
+		value := *e.Value
+		e.Value = &value
+	}
+	return &e
+}
@@ -31,13 +31,17 @@ func NoBurned(latest *es.Event[Request], snapshot *es.State[State, Request]) {
 			panic("status is burned")
 		}
 	}
-	if snapshot == nil {
-		if latest.EventType.String() == BurnEvent.String() {
+	if latest == nil {
+		return // 아직 쌓인 event 가 없음
+	}
+	if snapshot == nil || snapshot.State().LastEvent == nil {
+		if latest.EventType.Key() == BurnEvent.Key() {
 			panic("status is burned")
 		}
+		return
 	}
 	if latest.EventNo > snapshot.State().LastEvent.EventNo {
-		if latest.EventType.String() == BurnEvent.String() {
+		if latest.EventType.Key() == BurnEvent.Key() {
 			panic("status is burned")
 		}
 	}
@@ -2,6 +2,7 @@ package currency
 
 import (
 	es "eventsourcing"
+	"eventsourcing/estest"
 	"testing"
 )
 
@@ -36,20 +37,19 @@ func TestNoBurned(t *testing.T) {
 	}
 }
 
-func TestNoLock(t *testing.T) {
-	type args struct {
-		latest   *es.Event[Request]
-		snapshot *es.State[State, Request]
-	}
-	tests := []struct {
-		name string
-		args args
-	}{
-		// TODO: Add test cases.
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			NoLock(tt.args.latest, tt.args.snapshot)
-		})
-	}
+func TestNoBurnedScenario(t *testing.T) {
+	t.Run("accept_after_create", func(t *testing.T) {
+		estest.NewScenario[State, Request](t, Rule, Processor, Validator).
+			Given(&CreateAmountStateEvent, nil).
+			Given(&AddAmountEvent, &Request{Amount: 100}).
+			When(&MinusAmountEvent, &Request{Amount: 30}).
+			ThenState(&State{PartitionKey: estest.DefaultPartitionKey, Amount: 70, Status: NOTHING}, "lastEvent")
+	})
+	t.Run("reject_after_burn", func(t *testing.T) {
+		estest.NewScenario[State, Request](t, Rule, Processor, Validator).
+			Given(&CreateAmountStateEvent, nil).
+			Given(&BurnEvent, nil).
+			When(&AddAmountEvent, &Request{Amount: 100}).
+			ThenRejected("status is burned")
+	})
 }
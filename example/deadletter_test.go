@@ -0,0 +1,102 @@
+package example
+
+import (
+	es "eventsourcing"
+	"eventsourcing/example/currency"
+	"eventsourcing/example/storage"
+	"eventsourcing/manager"
+	"eventsourcing/memory"
+	"github.com/aws/smithy-go/ptr"
+	"testing"
+	"time"
+)
+
+func TestDeadLetterQuarantine(t *testing.T) {
+	// MinusAmount 의 Process 가 빠진 배포
+	processor := es.NewProcessor[currency.State, currency.Request]()
+	processor.SetProcess(currency.CreateAmountStateEvent, currency.CreateCurrencyState)
+	processor.SetProcess(currency.AddAmountEvent, currency.AddAmount)
+
+	clock := es.NewFakeClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	dl := memory.NewDeadLetterStorage[currency.Request]()
+	m := manager.NewBaseManager[currency.State, currency.Request](
+		&es.Rule{
+			AlwaysSnapshot: ptr.Bool(true),
+			FailurePolicy:  es.FailurePolicyPtr(es.QuarantineOnFailure),
+			FailureRetries: ptr.Int(1),
+		},
+		processor,
+		currency.Validator,
+		storage.NewCurrencyEventStorage(),
+		storage.NewCurrencySnapshotStorage(),
+		manager.WithClock[currency.State, currency.Request](clock),
+		manager.WithDeadLetterStorage[currency.State, currency.Request](dl),
+	)
+	admin := manager.NewDeadLetterAdmin[currency.State, currency.Request](m, dl)
+	pk := es.PartitionKey("poison")
+
+	_, _ = m.Put(pk, &currency.CreateAmountStateEvent, nil)
+	_, _ = m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 100})
+	_, _ = m.Put(pk, &currency.AddAmountEvent, nil)                             // 3. request 가 없음
+	_, _ = m.Put(pk, &currency.MinusAmountEvent, &currency.Request{Amount: 30}) // 4. Process 가 없음
+	_, _ = m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 1})
+
+	// 실패한 event 를 격리하고 snapshot 은 계속 갱신된다
+	if err := m.ApplyEvents(pk); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, _ := m.GetStateSnapshot(pk)
+	if snapshot.State().Amount != 101 || snapshot.State().LastEvent.EventNo != 5 {
+		t.Fatalf("snapshot = %s", snapshot)
+	}
+	letters, err := admin.List(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 2 || letters[0].EventNo != 3 || letters[1].EventNo != 4 {
+		t.Fatalf("letters = %s", es.JsonString(letters))
+	}
+	if letters[0].Attempts != 2 || letters[0].Error == "" {
+		t.Errorf("letters[0] = %s", es.JsonString(letters[0]))
+	}
+
+	// 정정 event 로 처리
+	correction, err := admin.Correct(pk, 3, &currency.AddAmountEvent, &currency.Request{Amount: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if correction.CausationId != letters[0].Event.EventId {
+		t.Errorf("correction.CausationId = %s, want %s", correction.CausationId, letters[0].Event.EventId)
+	}
+	snapshot, _ = m.GetStateSnapshot(pk)
+	if snapshot.State().Amount != 111 {
+		t.Errorf("amount after correction = %d, want 111", snapshot.State().Amount)
+	}
+
+	// Process 를 고친 뒤 격리 해제
+	processor.SetProcess(currency.MinusAmountEvent, currency.MinusAmount)
+	if err = admin.Release(pk, 4); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, _ = m.GetStateSnapshot(pk)
+	if snapshot.State().Amount != 81 {
+		t.Errorf("amount after release = %d, want 81", snapshot.State().Amount)
+	}
+	all, next, _ := admin.ListAll("", 10)
+	if len(all) != 1 || !all[0].Corrected() || next != "" {
+		t.Errorf("all = %s", es.JsonString(all))
+	}
+	if err = admin.Release(pk, 4); err == nil {
+		t.Error("released letter must not be found")
+	}
+}
+
+func TestDeadLetterHalt(t *testing.T) {
+	m := newCurrencyManager(&es.Rule{AlwaysSnapshot: ptr.Bool(true)}, es.NewFakeClock(time.Now()))
+	pk := es.PartitionKey("halt")
+	_, _ = m.Put(pk, &currency.CreateAmountStateEvent, nil)
+	_, _ = m.Put(pk, &currency.AddAmountEvent, nil)
+	if err := m.ApplyEvents(pk); err == nil {
+		t.Error("default policy must halt")
+	}
+}
@@ -0,0 +1,61 @@
+package example
+
+import (
+	es "eventsourcing"
+	"eventsourcing/example/currency"
+	"eventsourcing/example/storage"
+	"eventsourcing/integrity"
+	"eventsourcing/manager"
+	"sync"
+	"testing"
+)
+
+func TestHashChainConcurrentPut(t *testing.T) {
+	chained := integrity.NewEventStorage[currency.Request](storage.NewCurrencyEventStorage())
+	signer := integrity.NewHMACSigner("k1", []byte("secret"))
+
+	// 같은 storage 를 쓰는 두 서비스
+	managers := make([]manager.Manager[currency.State, currency.Request], 2)
+	for i := range managers {
+		managers[i] = manager.NewBaseManager[currency.State, currency.Request](
+			currency.Rule,
+			currency.Processor,
+			currency.Validator,
+			chained,
+			storage.NewCurrencySnapshotStorage(),
+			manager.WithSigner[currency.State, currency.Request](signer),
+			manager.WithSignatureVerifier[currency.State, currency.Request](signer),
+		)
+	}
+	pk := es.PartitionKey("concurrent")
+	if _, err := managers[0].Put(pk, &currency.CreateAmountStateEvent, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	const puts = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, puts)
+	for i := 0; i < puts; i++ {
+		wg.Add(1)
+		go func(m manager.Manager[currency.State, currency.Request]) {
+			defer wg.Done()
+			if _, err := m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 1}); err != nil {
+				errs <- err
+			}
+		}(managers[i%2])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	report, err := integrity.Verify[currency.Request](chained, pk)
+	if err != nil || !report.Intact() || report.Verified != puts+1 || report.HeadEventNo != puts+1 {
+		t.Fatalf("report = %s, %v", es.JsonString(report), err)
+	}
+	state, err := managers[1].GetLatestState(pk)
+	if err != nil || state.State().Amount != puts {
+		t.Errorf("state = %v, %v", state, err)
+	}
+}
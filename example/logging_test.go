@@ -0,0 +1,85 @@
+package example
+
+import (
+	es "eventsourcing"
+	"eventsourcing/example/currency"
+	"eventsourcing/example/storage"
+	"eventsourcing/manager"
+	"eventsourcing/memory"
+	"github.com/aws/smithy-go/ptr"
+	"sync"
+	"testing"
+)
+
+type logRecord struct {
+	level  es.Level
+	msg    string
+	fields map[string]any
+}
+
+// recordLogger | 남긴 로그를 모아두는 Logger
+type recordLogger struct {
+	records *[]logRecord
+	fields  []es.Field
+	locker  *sync.Mutex
+}
+
+func newRecordLogger() *recordLogger {
+	return &recordLogger{records: &[]logRecord{}, locker: &sync.Mutex{}}
+}
+
+func (l *recordLogger) Log(level es.Level, msg string, fields ...es.Field) {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	record := logRecord{level: level, msg: msg, fields: make(map[string]any)}
+	for _, f := range append(append([]es.Field(nil), l.fields...), fields...) {
+		record.fields[f.Key] = f.Value
+	}
+	*l.records = append(*l.records, record)
+}
+
+func (l *recordLogger) With(fields ...es.Field) es.Logger {
+	return &recordLogger{records: l.records, fields: append(append([]es.Field(nil), l.fields...), fields...), locker: l.locker}
+}
+
+func (l *recordLogger) find(msg string) *logRecord {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	for i := range *l.records {
+		if (*l.records)[i].msg == msg {
+			return &(*l.records)[i]
+		}
+	}
+	return nil
+}
+
+func TestManagerLogging(t *testing.T) {
+	logger := newRecordLogger()
+	m := manager.NewBaseManager[currency.State, currency.Request](
+		&es.Rule{AlwaysSnapshot: ptr.Bool(true), FailurePolicy: es.FailurePolicyPtr(es.QuarantineOnFailure)},
+		currency.Processor,
+		currency.Validator,
+		storage.NewCurrencyEventStorage(),
+		storage.NewCurrencySnapshotStorage(),
+		manager.WithLogger[currency.State, currency.Request](logger),
+		manager.WithDeadLetterStorage[currency.State, currency.Request](memory.NewDeadLetterStorage[currency.Request]()),
+	)
+	pk := es.PartitionKey("logging")
+	_, _ = m.Put(pk, &currency.CreateAmountStateEvent, nil)
+	_, _ = m.Put(pk, &currency.AddAmountEvent, nil)
+	if err := m.ApplyEvents(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	put := logger.find("event put")
+	if put == nil || put.level != es.LevelDebug || put.fields[es.FieldKeyPartitionKey] != "logging" || put.fields[es.FieldKeyDomain] != "currency" {
+		t.Errorf("event put = %+v", put)
+	}
+	quarantined := logger.find("event quarantined")
+	if quarantined == nil || quarantined.level != es.LevelWarn || quarantined.fields[es.FieldKeyEventNo] != 2 || quarantined.fields[es.FieldKeyError] == nil {
+		t.Errorf("event quarantined = %+v", quarantined)
+	}
+	if saved := logger.find("snapshot saved"); saved == nil || saved.fields[es.FieldKeyEventNo] != 1 {
+		t.Errorf("snapshot saved = %+v", saved)
+	}
+}
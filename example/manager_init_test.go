@@ -1,13 +1,16 @@
 package example
 
 import (
-	"context"
+	"errors"
 	es "eventsourcing"
 	"eventsourcing/example/currency"
+	"eventsourcing/example/storage"
+	"eventsourcing/manager"
 	"github.com/aws/smithy-go/ptr"
 	"log"
 	"math/rand"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -21,36 +24,33 @@ func (e *EventTypeAndRequest) String() string {
 	return es.JsonString(e)
 }
 
-type MakeRequestFunc func() *EventTypeAndRequest
+type MakeRequestFunc func(rnd *rand.Rand) *EventTypeAndRequest
 
 // event request 를 제네레이팅 해주는 func 정의
 var (
-	makeCreateRequest = func() *EventTypeAndRequest {
+	makeCreateRequest = func(rnd *rand.Rand) *EventTypeAndRequest {
 		return &EventTypeAndRequest{
 			Et:  &currency.CreateAmountStateEvent,
 			Req: nil, // request 가 없다
 		}
 	}
-	makeAddAmountRequest = func() *EventTypeAndRequest {
-		rand.Seed(time.Now().UnixNano())
+	makeAddAmountRequest = func(rnd *rand.Rand) *EventTypeAndRequest {
 		return &EventTypeAndRequest{
 			Et: &currency.AddAmountEvent,
 			Req: &currency.Request{
-				Amount: rand.Intn(1000),
+				Amount: rnd.Intn(1000),
 			},
 		}
 	}
-	makeMinusAmountRequest = func() *EventTypeAndRequest {
-		rand.Seed(time.Now().UnixNano())
+	makeMinusAmountRequest = func(rnd *rand.Rand) *EventTypeAndRequest {
 		return &EventTypeAndRequest{
 			Et: &currency.MinusAmountEvent,
 			Req: &currency.Request{
-				Amount: rand.Intn(500),
+				Amount: rnd.Intn(500),
 			},
 		}
 	}
-	makeChangeStatusRequest = func() *EventTypeAndRequest {
-		rand.Seed(time.Now().UnixNano())
+	makeChangeStatusRequest = func(rnd *rand.Rand) *EventTypeAndRequest {
 		return &EventTypeAndRequest{
 			Et: &currency.ChangeStatusEvent,
 			Req: &currency.Request{
@@ -58,27 +58,25 @@ var (
 			},
 		}
 	}
-	makeChangeValueRequest = func() *EventTypeAndRequest {
-		rand.Seed(time.Now().UnixNano())
+	makeChangeValueRequest = func(rnd *rand.Rand) *EventTypeAndRequest {
 		return &EventTypeAndRequest{
 			Et: &currency.ChangeValueEvent,
 			Req: &currency.Request{
 				Status: (*currency.Status)(ptr.Int(currency.CLAIM)),
-				Value:  ptr.String(strconv.Itoa(rand.Int())),
+				Value:  ptr.String(strconv.Itoa(rnd.Int())),
 			},
 		}
 	}
-	makeChangeValueV2Request = func() *EventTypeAndRequest {
-		rand.Seed(time.Now().UnixNano())
+	makeChangeValueV2Request = func(rnd *rand.Rand) *EventTypeAndRequest {
 		return &EventTypeAndRequest{
 			Et: &currency.ChangeValueV2Event,
 			Req: &currency.Request{
 				Status: (*currency.Status)(ptr.Int(currency.IDLE)),
-				Value:  ptr.String(strconv.Itoa(rand.Int())),
+				Value:  ptr.String(strconv.Itoa(rnd.Int())),
 			},
 		}
 	}
-	makeBurnRequest = func() *EventTypeAndRequest {
+	makeBurnRequest = func(rnd *rand.Rand) *EventTypeAndRequest {
 		return &EventTypeAndRequest{
 			Et:  &currency.BurnEvent,
 			Req: nil, // request 가 없다
@@ -86,72 +84,404 @@ var (
 	}
 )
 
+// newCurrencyManager | 테스트용 매니저, clock 과 순차 EventId 를 사용하여 재현 가능하게 만든다.
+func newCurrencyManager(rule *es.Rule, clock es.Clock) manager.Manager[currency.State, currency.Request] {
+	return newCurrencyManagerWith(rule, clock, storage.NewCurrencySnapshotStorage())
+}
+
+func newCurrencyManagerWith(
+	rule *es.Rule,
+	clock es.Clock,
+	ss es.StateSnapshotStorage[currency.State, currency.Request],
+) manager.Manager[currency.State, currency.Request] {
+	return manager.NewBaseManager[currency.State, currency.Request](
+		rule,
+		currency.Processor,
+		currency.Validator,
+		storage.NewCurrencyEventStorage(),
+		ss,
+		manager.WithClock[currency.State, currency.Request](clock),
+		manager.WithIdGenerator[currency.State, currency.Request](es.NewSequenceIdGenerator("test-")),
+	)
+}
+
 func TestCurrencyManager(t *testing.T) {
 	pk := es.PartitionKey("test_pk")
+	clock := es.NewFakeClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	m := newCurrencyManager(currency.Rule, clock)
 
-	ch := make(chan *EventTypeAndRequest, 10)
-	ctx, _ := context.WithTimeout(context.Background(), 30*time.Second)
-	go func() {
-		choiceMakeRequestFuncList := []MakeRequestFunc{
-			makeAddAmountRequest,
-			makeMinusAmountRequest,
-			makeChangeStatusRequest,
-			makeChangeValueRequest,
-			makeChangeValueV2Request,
-		}
-
-		// 첫 시작은 create 부터
-		ch <- makeCreateRequest()
-		rnd := rand.New(rand.NewSource(time.Now().Unix()))
-
-		for {
-			rnd.Seed(time.Now().UnixNano())
-			f := choiceMakeRequestFuncList[rnd.Intn(len(choiceMakeRequestFuncList))]
-			ch <- f()
-
-			select {
-			case <-ctx.Done():
-				close(ch)
-				return
-			default:
-				time.Sleep(1 * time.Second)
+	choiceMakeRequestFuncList := []MakeRequestFunc{
+		makeAddAmountRequest,
+		makeMinusAmountRequest,
+		makeChangeStatusRequest,
+		makeChangeValueRequest,
+		makeChangeValueV2Request,
+	}
+	rnd := rand.New(rand.NewSource(1))
+
+	// 1초마다 요청이 들어오고, 5초마다 snapshot 을 갱신하는 30초를 재현한다.
+	for second := 0; second < 30; second++ {
+		eventTypeRequest := makeCreateRequest(rnd) // 첫 시작은 create 부터
+		if second > 0 {
+			eventTypeRequest = choiceMakeRequestFuncList[rnd.Intn(len(choiceMakeRequestFuncList))](rnd)
+		}
+		log.Println("receive", eventTypeRequest)
+
+		if err := m.Validate(pk, eventTypeRequest.Et); err != nil {
+			t.Errorf("validation failed. %s - %s", eventTypeRequest.Et.String(), err)
+		} else if _, err = m.Put(pk, eventTypeRequest.Et, eventTypeRequest.Req); err != nil {
+			t.Errorf("put failed. %s - %s", eventTypeRequest.Et.String(), err)
+		}
+
+		clock.Advance(1 * time.Second)
+		if second%5 == 4 {
+			if err := m.ApplyEvents(pk); err != nil {
+				t.Errorf("update state snapshot failed. %s - %s", pk, err)
 			}
+			snapshot, _ := m.GetStateSnapshot(pk)
+			log.Println("current state", snapshot)
 		}
-	}()
+	}
 
-	go func() {
-		for eventTypeRequest := range ch {
-			log.Println("receive", eventTypeRequest)
+	if err := m.ApplyEvents(pk); err != nil {
+		t.Fatalf("update state snapshot failed. %s - %s", pk, err)
+	}
+	snapshot, err := m.GetStateSnapshot(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := m.GetLatestState(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diffs, err := es.DiffJson(latest.State(), snapshot.State())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) > 0 {
+		t.Errorf("snapshot is not equal to replayed state. %v", diffs)
+	}
+	if no := snapshot.State().GetLastEvent().EventNo; no != 30 {
+		t.Errorf("snapshot eventNo = %d, want 30", no)
+	}
+}
 
-			err := CurrencyEsManager.Validate(pk, eventTypeRequest.Et)
-			if err != nil {
-				t.Errorf("validation failed. %s - %s", eventTypeRequest.Et.String(), err)
-				continue
+func TestCurrencyManagerSnapshotTerm(t *testing.T) {
+	pk := es.PartitionKey("test_pk")
+	clock := es.NewFakeClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	m := newCurrencyManager(currency.Rule, clock) // MinSnapshotTerm 1초, MinEventNoTerm 5
+
+	put := func(n int) {
+		for i := 0; i < n; i++ {
+			if _, err := m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 1}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	assertSnapshotEventNo := func(want int) {
+		t.Helper()
+		if err := m.ApplyEvents(pk); err != nil {
+			t.Fatal(err)
+		}
+		snapshot, err := m.GetStateSnapshot(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if no := snapshot.State().GetLastEvent().EventNo; no != want {
+			t.Errorf("snapshot eventNo = %d, want %d", no, want)
+		}
+	}
+
+	if _, err := m.Put(pk, &currency.CreateAmountStateEvent, nil); err != nil {
+		t.Fatal(err)
+	}
+	assertSnapshotEventNo(1) // snapshot 이 없으면 바로 저장
+
+	put(1)
+	assertSnapshotEventNo(1) // term 이 지나지 않음
+
+	put(4)
+	assertSnapshotEventNo(1) // eventNo 차이가 term 과 같으면 넘어가지 않은 것
+
+	put(1)
+	assertSnapshotEventNo(7) // eventNo term 을 넘어감
+
+	put(1)
+	clock.Advance(1 * time.Second)
+	assertSnapshotEventNo(7) // 시간 차이가 term 과 같으면 넘어가지 않은 것
+
+	clock.Advance(time.Millisecond)
+	assertSnapshotEventNo(8) // 시간 term 을 넘어감
+}
+
+func TestCurrencyManagerValidateWithinSnapshotTerm(t *testing.T) {
+	pk := es.PartitionKey("test_pk")
+	clock := es.NewFakeClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	m := newCurrencyManager(currency.Rule, clock) // MinSnapshotTerm 1초, MinEventNoTerm 5
+
+	if _, err := m.Put(pk, &currency.CreateAmountStateEvent, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ApplyEvents(pk); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Put(pk, &currency.BurnEvent, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 1}); err != nil {
+		t.Fatal(err)
+	}
+	// snapshot 은 term 이 지나지 않아 burn 이전 state 로 남아있음
+	if err := m.ApplyEvents(pk); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, _ := m.GetStateSnapshot(pk)
+	if no := snapshot.State().GetLastEvent().EventNo; no != 1 {
+		t.Fatalf("snapshot eventNo = %d, want 1", no)
+	}
+
+	if err := m.Validate(pk, &currency.AddAmountEvent); err == nil {
+		t.Error("validate passed after burn")
+	}
+}
+
+func TestCurrencyManagerIdempotentPut(t *testing.T) {
+	pk := es.PartitionKey("test_pk")
+	clock := es.NewFakeClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	events := storage.NewCurrencyEventStorage()
+	newManager := func() manager.Manager[currency.State, currency.Request] {
+		return manager.NewBaseManager[currency.State, currency.Request](
+			currency.Rule,
+			currency.Processor,
+			currency.Validator,
+			events,
+			storage.NewCurrencySnapshotStorage(),
+			manager.WithClock[currency.State, currency.Request](clock),
+		)
+	}
+	m, other := newManager(), newManager() // 같은 storage 를 쓰는 두 서비스
+
+	if _, err := m.Put(pk, &currency.CreateAmountStateEvent, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// 타임아웃으로 같은 요청이 두 서비스에 동시에 재시도 되는 상황
+	var wg sync.WaitGroup
+	eventIds := make([]es.EventId, 10)
+	for i := range eventIds {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m := m
+			if i%2 == 1 {
+				m = other
 			}
-			err = CurrencyEsManager.Put(pk, eventTypeRequest.Et, eventTypeRequest.Req)
+			e, err := m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 100}, es.WithIdempotencyKey("req-1"))
 			if err != nil {
-				t.Errorf("put failed. %s - %s", eventTypeRequest.Et.String(), err)
-				continue
+				t.Error(err)
+				return
+			}
+			eventIds[i] = e.EventId
+		}(i)
+	}
+	wg.Wait()
+	for _, id := range eventIds {
+		if id != eventIds[0] {
+			t.Fatalf("duplicated put stored another event. %s, %s", eventIds[0], id)
+		}
+	}
+
+	state, err := m.GetLatestState(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.State().Amount != 100 {
+		t.Errorf("amount = %d, want 100", state.State().Amount)
+	}
+
+	// 같은 멱등키를 다른 요청에 쓰면 거절
+	if _, err = m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 200}, es.WithIdempotencyKey("req-1")); !errors.Is(err, es.ErrIdempotencyKeyReused) {
+		t.Errorf("different request err = %v", err)
+	}
+	if _, err = other.Put(pk, &currency.MinusAmountEvent, &currency.Request{Amount: 100}, es.WithIdempotencyKey("req-1")); !errors.Is(err, es.ErrIdempotencyKeyReused) {
+		t.Errorf("different event type err = %v", err)
+	}
+
+	// 멱등키를 기억하는 기간이 지나면 새 event 로 저장
+	clock.Advance(24*time.Hour + time.Second)
+	e, err := m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 100}, es.WithIdempotencyKey("req-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.EventId == eventIds[0] || e.EventNo != 3 {
+		t.Errorf("put after window = %s, want new event", e)
+	}
+}
+
+func TestCurrencyManagerTimeTravel(t *testing.T) {
+	pk := es.PartitionKey("test_pk")
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := es.NewFakeClock(start)
+	m := newCurrencyManager(currency.Rule, clock)
+
+	// eventNo 1 은 생성, 2 ~ 8 은 1초 간격으로 10 씩 더한다
+	if _, err := m.Put(pk, &currency.CreateAmountStateEvent, nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 7; i++ {
+		clock.Advance(time.Second)
+		if _, err := m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 10}); err != nil {
+			t.Fatal(err)
+		}
+		if i == 3 {
+			if err := m.ApplyEvents(pk); err != nil { // eventNo 5 에서 snapshot
+				t.Fatal(err)
 			}
 		}
-	}()
+	}
 
-	go func() {
-		for {
-			time.Sleep(5 * time.Second)
-			err := CurrencyEsManager.ApplyEvents(pk)
+	tests := []struct {
+		name    string
+		get     func() (*es.State[currency.State, currency.Request], error)
+		eventNo int
+		amount  int
+	}{
+		{"at_before_snapshot", func() (*es.State[currency.State, currency.Request], error) { return m.GetStateAt(pk, 3) }, 3, 20},
+		{"at_after_snapshot", func() (*es.State[currency.State, currency.Request], error) { return m.GetStateAt(pk, 7) }, 7, 60},
+		{"at_over_last", func() (*es.State[currency.State, currency.Request], error) { return m.GetStateAt(pk, 100) }, 8, 70},
+		{"as_of", func() (*es.State[currency.State, currency.Request], error) {
+			return m.GetStateAsOf(pk, start.Add(3500*time.Millisecond))
+		}, 4, 30},
+		{"as_of_first", func() (*es.State[currency.State, currency.Request], error) { return m.GetStateAsOf(pk, start) }, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := tt.get()
 			if err != nil {
-				t.Logf("update state snapshot failed. %s - %s", pk, err)
+				t.Fatal(err)
 			}
-			snapshot, _ := CurrencyEsManager.GetStateSnapshot(pk)
-			log.Println("current state", snapshot)
+			if no := state.State().GetLastEvent().EventNo; no != tt.eventNo {
+				t.Errorf("eventNo = %d, want %d", no, tt.eventNo)
+			}
+			if state.State().Amount != tt.amount {
+				t.Errorf("amount = %d, want %d", state.State().Amount, tt.amount)
+			}
+		})
+	}
+
+	if state, err := m.GetStateAsOf(pk, start.Add(-time.Second)); err != nil || state != nil {
+		t.Errorf("GetStateAsOf(before first event) = %v, %v, want nil", state, err)
+	}
+}
+
+func TestCurrencyManagerTimeTravelWithHistory(t *testing.T) {
+	pk := es.PartitionKey("test_pk")
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := es.NewFakeClock(start)
+	history := storage.NewCurrencySnapshotHistoryStorage(es.KeepLast(3))
+	m := newCurrencyManagerWith(&es.Rule{AlwaysSnapshot: ptr.Bool(true)}, clock, history)
+
+	// eventNo 1 은 생성, 2 ~ 10 은 하루 간격으로 10 씩 더하고, 매번 snapshot 을 남긴다
+	if _, err := m.Put(pk, &currency.CreateAmountStateEvent, nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 9; i++ {
+		clock.Advance(24 * time.Hour)
+		if _, err := m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 10}); err != nil {
+			t.Fatal(err)
 		}
-	}()
+		if err := m.ApplyEvents(pk); err != nil {
+			t.Fatal(err)
+		}
+	}
 
-	<-ctx.Done()
+	infos, err := history.GetSnapshotHistory(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 3 || infos[0].EventNo != 8 || infos[2].EventNo != 10 {
+		t.Fatalf("history = %s, want eventNo 8 ~ 10", es.JsonString(infos))
+	}
+
+	// history 의 snapshot 이 과거 상태를 그대로 들고 있어야 함
+	snapshot, err := history.GetSnapshotAtOrBefore(pk, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.State().Amount != 70 {
+		t.Errorf("snapshot(8).Amount = %d, want 70", snapshot.State().Amount)
+	}
+
+	state, err := m.GetStateAt(pk, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.State().Amount != 80 {
+		t.Errorf("GetStateAt(9).Amount = %d, want 80", state.State().Amount)
+	}
+	state, err = m.GetStateAsOf(pk, start.Add(2*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.State().Amount != 20 || state.State().GetLastEvent().EventNo != 3 {
+		t.Errorf("GetStateAsOf(day 2) = %s, want amount 20", state)
+	}
+}
 
-	log.Println("[events]")
-	log.Println(CurrencyEsManager.GetEvents(pk, 0))
-	log.Println("[snapshot + events replayed]")
-	log.Println(CurrencyEsManager.GetLatestState(pk))
+func TestCurrencyManagerReplayOnCopy(t *testing.T) {
+	clock := es.NewFakeClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	m := newCurrencyManager(&es.Rule{AlwaysSnapshot: ptr.Bool(true)}, clock)
+	pk := es.PartitionKey("copy_on_write")
+
+	_, _ = m.Put(pk, &currency.CreateAmountStateEvent, nil)
+	_, _ = m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 100})
+	if err := m.ApplyEvents(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	// 조회한 snapshot 을 바꿔도 저장된 snapshot 은 바뀌지 않는다
+	snapshot, _ := m.GetStateSnapshot(pk)
+	snapshot.State().Amount = 999
+
+	// AddAmount 는 적용되고, request 가 없는 MinusAmount 에서 replay 가 실패한다
+	_, _ = m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 10})
+	_, _ = m.Put(pk, &currency.MinusAmountEvent, nil)
+	err := m.ApplyEvents(pk)
+	if err == nil {
+		t.Fatal("replay must fail")
+	}
+	var esErr *es.EventSourceError
+	if !errors.As(err, &esErr) || esErr.EventNo() != 4 || !errors.Is(err, currency.ErrEmptyRequest) {
+		t.Errorf("err = %v, want failed eventNo and cause", err)
+	}
+
+	snapshot, _ = m.GetStateSnapshot(pk)
+	if snapshot.State().Amount != 100 || snapshot.State().LastEvent.EventNo != 2 {
+		t.Errorf("snapshot is corrupted. %s", snapshot)
+	}
+}
+
+func TestCurrencyManagerRegistry(t *testing.T) {
+	m := manager.NewBaseManager[currency.State, currency.Request](
+		currency.Rule,
+		currency.Processor,
+		currency.Validator,
+		storage.NewCurrencyEventStorage(),
+		storage.NewCurrencySnapshotStorage(),
+		manager.WithRegistry[currency.State, currency.Request](currency.Registry),
+	)
+	pk := es.PartitionKey("registry")
+	if _, err := m.Put(pk, &currency.CreateAmountStateEvent, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// 이름은 같아도 등록되지 않은 version 은 거절
+	unknown := currency.AddAmountEvent
+	unknown.Version = "v9"
+	if _, err := m.Put(pk, &unknown, &currency.Request{Amount: 1}); !errors.Is(err, es.ErrUnregisteredEventType) {
+		t.Errorf("unregistered err = %v", err)
+	}
+	if events, _ := m.GetEvents(pk, 0); len(events) != 1 {
+		t.Errorf("len(events) = %d, want 1", len(events))
+	}
 }
@@ -0,0 +1,105 @@
+package example
+
+import (
+	"context"
+	es "eventsourcing"
+	"eventsourcing/example/currency"
+	"eventsourcing/example/storage"
+	"eventsourcing/manager"
+	"eventsourcing/memory"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRebuilder(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := es.NewFakeClock(start)
+	eventStorage := storage.NewCurrencyEventStorage()
+	snapshotStorage := storage.NewCurrencySnapshotStorage()
+	m := manager.NewBaseManager[currency.State, currency.Request](
+		currency.Rule,
+		currency.Processor,
+		currency.Validator,
+		eventStorage,
+		snapshotStorage,
+		manager.WithClock[currency.State, currency.Request](clock),
+	)
+
+	// 20개의 partition 에 amount 를 쌓고, 잘못된 snapshot 을 저장해 둔다
+	pks := make([]es.PartitionKey, 20)
+	for i := range pks {
+		pks[i] = es.PartitionKey(fmt.Sprintf("pk_%02d", i))
+		if _, err := m.Put(pks[i], &currency.CreateAmountStateEvent, nil); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Put(pks[i], &currency.AddAmountEvent, &currency.Request{Amount: i}); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.ApplyEvents(pks[i]); err != nil {
+			t.Fatal(err)
+		}
+		snapshot, _ := m.GetStateSnapshot(pks[i])
+		snapshot.State().Amount = -1 // bug 가 있던 process 로 만들어진 snapshot
+		if err := snapshotStorage.SaveSnapshot(pks[i], snapshot); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(time.Minute)
+	}
+	// process 가 없는 event 가 쌓인 partition
+	if _, err := m.Put("pk_broken", &es.EventType{Domain: "currency", Name: "unknown", Version: "v1"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint := memory.NewRebuildCheckpointStorage()
+	_ = checkpoint.MarkRebuilt("job", pks[0]) // 이전 실행에서 끝난 partition
+
+	var progressCalls int
+	rebuilder := manager.NewRebuilder[currency.State, currency.Request](m, eventStorage, manager.RebuildConfig{
+		Workers:    3,
+		PageSize:   7,
+		Checkpoint: checkpoint,
+		OnProgress: func(progress manager.RebuildProgress) {
+			progressCalls++
+			clock.Advance(time.Second)
+		},
+		Clock: clock,
+	})
+	since := start.Add(10 * time.Minute)
+	report, err := rebuilder.Rebuild(context.Background(), "job", manager.RebuildFilter{Domain: "currency", ChangedSince: &since})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Listed != 21 || report.Resumed != 1 || report.Filtered != 9 || report.Rebuilt != 10 || report.Failed != 1 {
+		t.Errorf("report = %s", es.JsonString(report.RebuildProgress))
+	}
+	if len(report.Failures) != 1 || report.Failures[0].PartitionKey != "pk_broken" {
+		t.Errorf("failures = %v", report.Failures)
+	}
+	if progressCalls != report.Listed*2 { // 나열할 때, 끝날 때
+		t.Errorf("progress calls = %d, want %d", progressCalls, report.Listed*2)
+	}
+	if report.Elapsed != time.Duration(progressCalls)*time.Second {
+		t.Errorf("elapsed = %v, want %ds", report.Elapsed, progressCalls)
+	}
+	for i, pk := range pks {
+		snapshot, _ := m.GetStateSnapshot(pk)
+		want := -1
+		if i >= 10 {
+			want = i
+		}
+		if snapshot.State().Amount != want {
+			t.Errorf("%s amount = %d, want %d", pk, snapshot.State().Amount, want)
+		}
+	}
+
+	// 같은 jobId 로 다시 실행하면 끝난 partition 은 건너뜀
+	report, err = rebuilder.Rebuild(context.Background(), "job", manager.RebuildFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Resumed != 11 || report.Rebuilt != 9 || report.Failed != 1 {
+		t.Errorf("resumed report = %s", es.JsonString(report.RebuildProgress))
+	}
+}
@@ -0,0 +1,83 @@
+package example
+
+import (
+	"errors"
+	es "eventsourcing"
+	"eventsourcing/encryption"
+	"eventsourcing/example/currency"
+	"eventsourcing/example/storage"
+	"eventsourcing/manager"
+	"eventsourcing/memory"
+	"github.com/aws/smithy-go/ptr"
+	"testing"
+)
+
+func TestCryptoShredding(t *testing.T) {
+	raw := storage.NewCurrencyEventStorage()
+	rawSnapshots := storage.NewCurrencySnapshotStorage()
+	keys := memory.NewKeyStore()
+	newManager := func(policy es.ShreddedPolicy) manager.Manager[currency.State, currency.Request] {
+		return manager.NewBaseManager[currency.State, currency.Request](
+			&es.Rule{AlwaysSnapshot: ptr.Bool(true), ShreddedPolicy: es.ShreddedPolicyPtr(policy)},
+			currency.Processor,
+			currency.Validator,
+			encryption.NewEventStorage[currency.Request](raw, keys),
+			encryption.NewSnapshotStorage[currency.State, currency.Request](rawSnapshots, keys),
+		)
+	}
+	m := newManager(es.SkipShredded)
+	pk := es.PartitionKey("gdpr")
+
+	_, _ = m.Put(pk, &currency.CreateAmountStateEvent, nil)
+	_, _ = m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 100})
+	if err := m.ApplyEvents(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	// 저장소에는 암호문만 남는다
+	stored, _ := raw.GetEvents(pk)
+	if stored[1].Request != nil || stored[1].Encrypted == nil {
+		t.Fatalf("stored event = %s", es.JsonString(stored[1]))
+	}
+	sealed, _ := rawSnapshots.GetSnapshot(pk)
+	if sealed.Sealed() == nil || sealed.State().Amount != 0 || sealed.State().LastEvent.EventNo != 2 {
+		t.Fatalf("stored snapshot = %s", sealed)
+	}
+
+	// manager 는 복호화된 값을 본다
+	snapshot, _ := m.GetStateSnapshot(pk)
+	if snapshot.State().Amount != 100 {
+		t.Fatalf("snapshot = %s", snapshot)
+	}
+
+	// key 를 지우면 snapshot 은 없는 것으로, event 는 Shredded 로 조회된다
+	if err := keys.DeleteKeys(pk); err != nil {
+		t.Fatal(err)
+	}
+	if snapshot, _ = m.GetStateSnapshot(pk); snapshot != nil {
+		t.Errorf("shredded snapshot = %s", snapshot)
+	}
+	events, _ := m.GetEvents(pk, 0)
+	if !events[1].Shredded() {
+		t.Errorf("events[1] = %s", events[1])
+	}
+
+	// SkipShredded (기본) : Process 를 수행하지 않고 건너뜀
+	state, err := m.GetLatestState(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.State().Amount != 0 || state.State().LastEvent.EventNo != 2 {
+		t.Errorf("skipped state = %s", state)
+	}
+
+	// HaltOnShredded : replay 를 멈춤
+	if _, err = newManager(es.HaltOnShredded).GetLatestState(pk); !errors.Is(err, es.ErrShreddedEvent) || !errors.Is(err, es.ErrCommand) {
+		t.Errorf("halt err = %v", err)
+	}
+
+	// ApplyShredded : Request 가 nil 인 event 로 Process 를 수행, currency 의 Process 는 거부한다
+	if _, err = newManager(es.ApplyShredded).GetLatestState(pk); !errors.Is(err, currency.ErrEmptyRequest) {
+		t.Errorf("apply err = %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package example
+
+import (
+	"crypto/ed25519"
+	"errors"
+	es "eventsourcing"
+	"eventsourcing/encryption"
+	"eventsourcing/estest"
+	"eventsourcing/example/currency"
+	"eventsourcing/example/storage"
+	"eventsourcing/integrity"
+	"eventsourcing/manager"
+	"eventsourcing/memory"
+	"testing"
+)
+
+func TestEventSigning(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(nil)
+	ring := integrity.NewKeyRing()
+	ring.Rotate("wallet-1", integrity.NewEd25519Signer("wallet-1", private))
+	ring.Add("legacy", integrity.NewHMACSigner("legacy", []byte("secret")))
+
+	store := estest.NewTamperedEventStorage[currency.Request](storage.NewCurrencyEventStorage())
+	newManager := func(policy es.SignaturePolicy, signer es.Signer, logger es.Logger) manager.Manager[currency.State, currency.Request] {
+		opts := []manager.Option[currency.State, currency.Request]{
+			manager.WithSignatureVerifier[currency.State, currency.Request](ring),
+			manager.WithLogger[currency.State, currency.Request](logger),
+		}
+		if signer != nil {
+			opts = append(opts, manager.WithSigner[currency.State, currency.Request](signer))
+		}
+		return manager.NewBaseManager[currency.State, currency.Request](
+			&es.Rule{SignaturePolicy: es.SignaturePolicyPtr(policy)},
+			currency.Processor,
+			currency.Validator,
+			store,
+			storage.NewCurrencySnapshotStorage(),
+			opts...,
+		)
+	}
+	pk := es.PartitionKey("signed")
+
+	// wallet 서비스가 서명한 event
+	m := newManager(es.RejectInvalidSignature, ring, es.NopLogger{})
+	created, err := m.Put(pk, &currency.CreateAmountStateEvent, nil)
+	if err != nil || created.KeyId != "wallet-1" || len(created.Signature) == 0 {
+		t.Fatalf("created = %s, %v", created, err)
+	}
+
+	// key 를 바꿔도 이전 key 의 서명은 검증된다
+	rotated := integrity.NewHMACSigner("wallet-2", []byte("rotated"))
+	ring.Rotate("wallet-2", rotated)
+	added, _ := m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 100})
+	if added.KeyId != "wallet-2" {
+		t.Errorf("added.KeyId = %s", added.KeyId)
+	}
+	state, err := m.GetLatestState(pk)
+	if err != nil || state.State().Amount != 100 {
+		t.Fatalf("state = %v, %v", state, err)
+	}
+
+	// 서명하지 않은 서비스가 쌓은 event
+	_, _ = newManager(es.RejectInvalidSignature, nil, es.NopLogger{}).Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 1})
+	if _, err = m.GetLatestState(pk); !errors.Is(err, es.ErrMissingSignature) || !errors.Is(err, es.ErrCommand) {
+		t.Errorf("reject err = %v", err)
+	}
+	if _, err = m.GetEvents(pk, 0); !errors.Is(err, es.ErrMissingSignature) {
+		t.Errorf("GetEvents err = %v", err)
+	}
+
+	// Warn : 로그만 남기고 계속한다
+	logger := newRecordLogger()
+	state, err = newManager(es.WarnInvalidSignature, nil, logger).GetLatestState(pk)
+	if err != nil || state.State().Amount != 101 {
+		t.Fatalf("warn state = %v, %v", state, err)
+	}
+	if record := logger.find("invalid event signature"); record == nil || record.level != es.LevelWarn || record.fields[es.FieldKeyEventNo] != 3 {
+		t.Errorf("warn record = %+v", record)
+	}
+
+	// Ignore : 검증하지 않는다
+	if _, err = newManager(es.IgnoreSignature, nil, es.NopLogger{}).GetLatestState(pk); err != nil {
+		t.Errorf("ignore err = %v", err)
+	}
+
+	// 서명한 뒤 고친 event 는 검증에 실패한다
+	tampered := *added
+	tampered.Request = &currency.Request{Amount: 1000}
+	store.Tamper(&tampered)
+	if _, err = m.GetStateAt(pk, 2); !errors.Is(err, es.ErrInvalidSignature) {
+		t.Errorf("tampered err = %v", err)
+	}
+
+	// key 를 폐기하면 그 key 로 서명한 event 는 검증에 실패한다. 검증만 하는 쪽은 public key 로 검증한다
+	ring.Retire("wallet-1")
+	if _, err = m.GetEvents(pk, 0); !errors.Is(err, es.ErrUnknownKey) {
+		t.Errorf("retired err = %v", err)
+	}
+	ring.Add("wallet-1", integrity.NewEd25519Verifier("wallet-1", public))
+	if err = es.VerifyEvent[currency.Request](ring, created); err != nil {
+		t.Errorf("public key verify err = %v", err)
+	}
+}
+
+func TestEventSigning_Shredded(t *testing.T) {
+	signer := integrity.NewHMACSigner("k1", []byte("secret"))
+	keys := memory.NewKeyStore()
+	raw := estest.NewTamperedEventStorage[currency.Request](storage.NewCurrencyEventStorage())
+	m := manager.NewBaseManager[currency.State, currency.Request](
+		nil,
+		currency.Processor,
+		currency.Validator,
+		encryption.NewEventStorage[currency.Request](raw, keys),
+		storage.NewCurrencySnapshotStorage(),
+		manager.WithSigner[currency.State, currency.Request](signer),
+		manager.WithSignatureVerifier[currency.State, currency.Request](signer),
+	)
+	pk := es.PartitionKey("shredded")
+	_, _ = m.Put(pk, &currency.CreateAmountStateEvent, nil)
+	_, _ = m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 100})
+	_, _ = m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 200})
+	if _, err := m.GetEvents(pk, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// key 를 지워도 서명은 암호문으로 검증한다
+	_ = keys.DeleteKeys(pk)
+	events, err := m.GetEvents(pk, 0)
+	if err != nil || !events[1].Shredded() {
+		t.Fatalf("shredded events = %s, %v", es.JsonString(events), err)
+	}
+
+	// 읽을 수 없는 event 라도 envelope 을 고치면 검증에 실패한다
+	forged := *events[1]
+	forged.Actor = "intruder"
+	raw.Tamper(&forged)
+	if _, err = m.GetEvents(pk, 0); !errors.Is(err, es.ErrInvalidSignature) {
+		t.Errorf("forged envelope err = %v", err)
+	}
+
+	// 다른 event 의 암호문으로 바꿔도 검증에 실패한다
+	swapped := *events[1]
+	swapped.Encrypted = events[2].Encrypted
+	raw.Tamper(&swapped)
+	if _, err = m.GetEvents(pk, 0); !errors.Is(err, es.ErrInvalidSignature) {
+		t.Errorf("swapped payload err = %v", err)
+	}
+}
@@ -3,7 +3,7 @@ module eventsourcing
 go 1.18
 
 require (
-	github.com/aws/smithy-go v1.13.2 // indirect
-	github.com/pkg/errors v0.9.1 // indirect
-	github.com/rs/xid v1.4.0 // indirect
+	github.com/aws/smithy-go v1.13.2
+	github.com/pkg/errors v0.9.1
+	github.com/rs/xid v1.4.0
 )
@@ -0,0 +1,48 @@
+package eventsourcing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Hash Chain
+//
+// 저장된 event 가 나중에 고쳐지지 않았음을 증명하기 위해, partition 의 event 마다 내용의 hash 와 이전 event 의 hash 를 함께 저장한다.
+// event 하나를 고치면 그 event 의 Hash 가, event 를 빼거나 끼워넣으면 다음 event 의 PrevHash 가 맞지 않게 된다.
+// chain 전체를 다시 계산하는 변조는 chain head 에 서명한 HashCheckpoint 로 찾아낸다.
+// chain 을 만들고 검사하는 일은 integrity package 가 담당한다.
+
+// HashEvent | event 의 hash, Hash 를 뺀 event(PrevHash 포함)를 json 으로 변환해서 계산한 SHA-256 hex 문자열
+// 저장소는 event 를 json 으로 같은 값이 나오도록 보존해야 한다. (시간의 정밀도, Request 의 field 등)
+// 암호화한 event 는 저장된 모양(암호문)으로 계산하므로, 복호화해서 읽은 event 의 Request 는 뺀다.
+func HashEvent[R any](e *Event[R]) (string, error) {
+	content := *e
+	content.Hash = ""
+	if content.Encrypted != nil {
+		content.Request = nil
+	}
+	b, err := json.Marshal(&content)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// HashCheckpoint | 서명한 partition 의 chain head
+type HashCheckpoint struct {
+	PartitionKey PartitionKey `json:"partitionKey"`
+	EventNo      int          `json:"eventNo"`   // 서명할 때의 마지막 event 번호
+	Hash         string       `json:"hash"`      // 서명할 때의 마지막 event 의 Hash
+	CreatedAt    time.Time    `json:"createdAt"` // 서명한 시간
+	KeyId        string       `json:"keyId"`     // 서명한 key 의 id
+	Signature    []byte       `json:"signature"`
+}
+
+// SigningBytes | 서명 대상, 서명(KeyId, Signature)을 뺀 모든 값
+func (c *HashCheckpoint) SigningBytes() []byte {
+	return []byte(fmt.Sprintf("%s\n%d\n%s\n%s", c.PartitionKey, c.EventNo, c.Hash, c.CreatedAt.UTC().Format(time.RFC3339Nano)))
+}
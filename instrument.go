@@ -0,0 +1,174 @@
+package eventsourcing
+
+import (
+	"sync"
+	"time"
+)
+
+// Observability
+//
+// Manager 와 Processor 는 작업마다 Instrumentation 을 호출한다.
+// - StartSpan : 작업을 시작할 때 호출, tracing 의 span 을 시작할 때 사용한다.
+// - Observe : 작업이 끝날 때 호출, 작업 종류, domain, event type, 걸린 시간, 결과, replay 한 event 수, snapshot 사용 여부를 전달한다.
+// Prometheus 로 내보내는 구현체는 metrics 패키지 참고.
+
+// Operation | 계측하는 작업의 종류
+type Operation string
+
+const (
+	OpValidate        Operation = "validate"
+	OpPut             Operation = "put"
+	OpApplyEvents     Operation = "apply_events"
+	OpGetState        Operation = "get_state" // GetLatestState, GetStateAt, GetStateAsOf
+	OpRebuildSnapshot Operation = "rebuild_snapshot"
+	OpReplay          Operation = "replay"
+	OpProcess         Operation = "process"
+)
+
+// Observation | 끝난 작업 하나의 계측 정보
+type Observation struct {
+	Operation      Operation
+	Domain         Domain     // 알 수 없으면 빈 값
+	EventType      *EventType // nullable, 작업 대상 event type
+	PartitionKey   PartitionKey
+	Duration       time.Duration
+	Err            error // nil 이면 성공
+	EventsReplayed int   // replay 한 event 수
+	SnapshotHit    *bool // nullable, snapshot 을 찾아서 사용했는지 여부, snapshot 을 조회하지 않는 작업은 nil
+}
+
+// Outcome | 작업 결과 "ok", "error"
+func (o *Observation) Outcome() string {
+	if o.Err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+// Span | tracing 의 구간, 작업이 끝나면 계측 정보와 함께 End 를 호출한다.
+type Span interface {
+	End(o *Observation)
+}
+
+// Instrumentation | Manager, Processor 의 작업을 계측하는 인터페이스
+type Instrumentation interface {
+	StartSpan(op Operation, pk PartitionKey, et *EventType) Span // 작업을 시작할 때 호출
+	Observe(o *Observation)                                      // 작업이 끝날 때 호출
+}
+
+// NopInstrumentation | 아무것도 하지 않는 Instrumentation, 기본 값
+type NopInstrumentation struct{}
+
+func (NopInstrumentation) StartSpan(Operation, PartitionKey, *EventType) Span { return nopSpan{} }
+func (NopInstrumentation) Observe(*Observation)                               {}
+
+type nopSpan struct{}
+
+func (nopSpan) End(*Observation) {}
+
+// SpanFunc | 함수로 Span 을 만들 때 사용
+type SpanFunc func(o *Observation)
+
+func (f SpanFunc) End(o *Observation) { f(o) }
+
+// Instrumentations | 여러 Instrumentation 을 순서대로 호출하는 Instrumentation, ex) metrics + tracing
+func Instrumentations(list ...Instrumentation) Instrumentation {
+	return multiInstrumentation(list)
+}
+
+type multiInstrumentation []Instrumentation
+
+func (m multiInstrumentation) StartSpan(op Operation, pk PartitionKey, et *EventType) Span {
+	spans := make(multiSpan, len(m))
+	for i, inst := range m {
+		spans[i] = inst.StartSpan(op, pk, et)
+	}
+	return spans
+}
+
+func (m multiInstrumentation) Observe(o *Observation) {
+	for _, inst := range m {
+		inst.Observe(o)
+	}
+}
+
+type multiSpan []Span
+
+func (m multiSpan) End(o *Observation) {
+	for i := len(m) - 1; i >= 0; i-- { // 나중에 시작한 span 부터 끝냄
+		m[i].End(o)
+	}
+}
+
+// Tracker | 작업 하나를 계측하는 도우미, StartTracking 으로 시작하고 Finish 로 끝낸다.
+//
+//	t := StartTracking(inst, OpPut, pk, et)
+//	defer func() { t.Finish(err) }()
+type Tracker struct {
+	inst        Instrumentation
+	span        Span
+	start       time.Time
+	observation Observation
+	locker      sync.Mutex
+}
+
+// StartTracking | 작업을 시작한다. et 가 있으면 Domain 도 기록한다.
+func StartTracking(inst Instrumentation, op Operation, pk PartitionKey, et *EventType) *Tracker {
+	if inst == nil {
+		inst = NopInstrumentation{}
+	}
+	t := &Tracker{
+		inst:  inst,
+		span:  inst.StartSpan(op, pk, et),
+		start: time.Now(),
+		observation: Observation{
+			Operation:    op,
+			PartitionKey: pk,
+		},
+	}
+	t.SetEventType(et)
+	return t
+}
+
+// SetEventType | 작업 대상 event type 과 domain 을 기록한다. 시작할 때 알 수 없었던 경우 사용
+func (t *Tracker) SetEventType(et *EventType) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	if et != nil {
+		t.observation.EventType = et
+		t.observation.Domain = et.Domain
+	}
+}
+
+// SetDomain | 작업 대상 domain 만 기록한다. 여러 event type 을 다루는 작업(replay, apply)은 event type 을 비워둔다.
+func (t *Tracker) SetDomain(domain Domain) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	t.observation.Domain = domain
+}
+
+// AddEventsReplayed | replay 한 event 수를 더한다.
+func (t *Tracker) AddEventsReplayed(n int) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	t.observation.EventsReplayed += n
+}
+
+// SetSnapshotHit | snapshot 을 찾아서 사용했는지 기록한다.
+func (t *Tracker) SetSnapshotHit(hit bool) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	t.observation.SnapshotHit = &hit
+}
+
+// Finish | 작업을 끝내고 span 과 Instrumentation 에 계측 정보를 전달한다.
+func (t *Tracker) Finish(err error) {
+	t.locker.Lock()
+	o := t.observation
+	t.locker.Unlock()
+
+	o.Duration = time.Since(t.start)
+	o.Err = err
+	t.span.End(&o)
+	t.inst.Observe(&o)
+}
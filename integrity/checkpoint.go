@@ -0,0 +1,173 @@
+package integrity
+
+import (
+	"context"
+	"errors"
+	es "eventsourcing"
+	"fmt"
+	"time"
+)
+
+// Signed Checkpoint
+//
+// hash chain 만으로는 chain 전체를 다시 계산한 변조를 알 수 없다.
+// Checkpointer 는 주기적으로 partition 의 chain 을 검사하고, 끊어지지 않았으면 chain head 에 서명해서 HashCheckpointStorage 에 남긴다.
+// 검사할 때는 chain 과 함께 남아있는 checkpoint 의 서명과, checkpoint 의 eventNo 에 있는 event 의 Hash 를 확인한다.
+
+// ErrBrokenChain | 끊어진 chain 에는 checkpoint 를 남기지 않음
+var ErrBrokenChain = errors.New("hash chain is broken")
+
+// CheckpointConfig | Checkpointer 설정
+type CheckpointConfig struct {
+	Interval time.Duration // default 1 hour, Run 이 checkpoint 를 남기는 주기
+	PageSize int           // default 100, partition 목록을 한 번에 가져오는 수
+	Clock    es.Clock      // default DefaultClock, checkpoint 의 CreatedAt
+}
+
+// Checkpointer | partition 의 chain head 에 서명하고 검사하는 작업
+type Checkpointer[R any] struct {
+	es       es.EventStorage[R]
+	cs       es.HashCheckpointStorage
+	signer   es.Signer
+	verifier es.SignatureVerifier
+	config   CheckpointConfig
+}
+
+// NewCheckpointer | storage 의 chain head 에 signer 로 서명해서 cs 에 남기고, verifier 로 서명을 검증한다.
+func NewCheckpointer[R any](
+	storage es.EventStorage[R],
+	cs es.HashCheckpointStorage,
+	signer es.Signer,
+	verifier es.SignatureVerifier,
+	config CheckpointConfig,
+) *Checkpointer[R] {
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	if config.PageSize <= 0 {
+		config.PageSize = 100
+	}
+	if config.Clock == nil {
+		config.Clock = es.DefaultClock
+	}
+	return &Checkpointer[R]{
+		es:       storage,
+		cs:       cs,
+		signer:   signer,
+		verifier: verifier,
+		config:   config,
+	}
+}
+
+// Checkpoint | pk 의 chain 을 검사하고 head 에 서명한다. event 가 없거나 마지막 checkpoint 이후 event 가 없으면 nil 을 돌려준다.
+func (c *Checkpointer[R]) Checkpoint(pk es.PartitionKey) (*es.HashCheckpoint, error) {
+	report, err := c.Verify(pk)
+	if err != nil {
+		return nil, err
+	}
+	if !report.Intact() {
+		return nil, ErrBrokenChain
+	}
+	if report.Head == "" {
+		return nil, nil
+	}
+	last, err := c.cs.GetLastCheckpoint(pk)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil && last.EventNo == report.HeadEventNo {
+		return nil, nil
+	}
+
+	checkpoint := &es.HashCheckpoint{
+		PartitionKey: pk,
+		EventNo:      report.HeadEventNo,
+		Hash:         report.Head,
+		CreatedAt:    c.config.Clock.Now(),
+	}
+	if checkpoint.KeyId, checkpoint.Signature, err = c.signer.Sign(checkpoint.SigningBytes()); err != nil {
+		return nil, err
+	}
+	if err = c.cs.SaveCheckpoint(checkpoint); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// CheckpointAll | 모든 partition 에 Checkpoint 를 수행하고 새로 남긴 checkpoint 수를 돌려준다.
+// chain 이 끊어진 partition 은 건너뛰고 ErrBrokenChain 을 감싼 에러로 알려준다.
+func (c *Checkpointer[R]) CheckpointAll(ctx context.Context) (int, error) {
+	var broken []es.PartitionKey
+	created := 0
+	cursor := ""
+	for {
+		page, next, err := c.es.ListPartitions(cursor, c.config.PageSize)
+		if err != nil {
+			return created, es.NewEventStorageError(err)
+		}
+		for _, stat := range page {
+			if err = ctx.Err(); err != nil {
+				return created, err
+			}
+			checkpoint, err := c.Checkpoint(stat.PartitionKey)
+			if errors.Is(err, ErrBrokenChain) {
+				broken = append(broken, stat.PartitionKey)
+				continue
+			}
+			if err != nil {
+				return created, err
+			}
+			if checkpoint != nil {
+				created++
+			}
+		}
+		if cursor = next; cursor == "" {
+			break
+		}
+	}
+	if len(broken) > 0 {
+		return created, fmt.Errorf("%w. pks(%v)", ErrBrokenChain, broken)
+	}
+	return created, nil
+}
+
+// Run | ctx 가 취소될 때 까지 Interval 마다 CheckpointAll 을 수행한다. onError 는 nullable
+func (c *Checkpointer[R]) Run(ctx context.Context, onError func(err error)) error {
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := c.CheckpointAll(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Verify | pk 의 chain 과 checkpoint 를 검사한다.
+// chain 이 끊어지지 않았다면, checkpoint 의 서명과 checkpoint 의 eventNo 에 있는 event 의 Hash 를 확인한다.
+func (c *Checkpointer[R]) Verify(pk es.PartitionKey) (*ChainReport, error) {
+	report, err := Verify[R](c.es, pk)
+	if err != nil || !report.Intact() {
+		return report, err
+	}
+	checkpoints, err := c.cs.GetCheckpoints(pk)
+	if err != nil {
+		return nil, err
+	}
+	for _, checkpoint := range checkpoints {
+		err = c.verifier.Verify(checkpoint.KeyId, checkpoint.SigningBytes(), checkpoint.Signature)
+		if err != nil {
+			report.Broken = &BrokenLink{EventNo: checkpoint.EventNo, Reason: BrokenSignature, Actual: err.Error()}
+			return report, nil
+		}
+		if hash := report.hashes[checkpoint.EventNo]; hash != checkpoint.Hash {
+			report.Broken = &BrokenLink{EventNo: checkpoint.EventNo, Reason: BrokenCheckpoint, Expected: checkpoint.Hash, Actual: hash}
+			return report, nil
+		}
+	}
+	return report, nil
+}
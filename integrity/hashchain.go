@@ -0,0 +1,52 @@
+package integrity
+
+import (
+	"errors"
+	es "eventsourcing"
+)
+
+// Hash Chain Decorator
+//
+// EventStorage 는 AppendEvent 에서 partition 의 마지막 event 의 Hash 를 PrevHash 로, event 의 hash 를 Hash 로 채워서 저장한다.
+// - 마지막 event 는 감싼 storage 가 번호 발급과 함께 원자적으로 알려주므로(EventAppend.Seal), 여러 프로세스가 같은 storage 에 써도 chain 이 갈라지지 않는다.
+// - 마지막 event 를 보고 쓰는 사이에 끼어들 수 있는 AddEvent 는 ErrAppendOnly 로 거부한다.
+// - Hash 는 EventAppend.Seal 에서 채운 서명까지 포함해서 계산한다.
+// - 암호화와 함께 쓸 때는 encryption decorator 안쪽에 두어 암호문에 hash 를 건다. key 를 지워도 chain 은 검증할 수 있다.
+// 감싼 storage 의 선택 인터페이스는 eventsourcing.DecorateEventStorage 로 이어받는다.
+
+// ErrAppendOnly | hash chain 에 AppendEvent 가 아닌 방법으로 event 를 저장하려 함
+var ErrAppendOnly = es.Permanent(errors.New("hash chain accepts events only through AppendEvent"))
+
+var _ es.EventStorage[any] = &EventStorage[any]{}
+
+// EventStorage | 저장하는 event 를 partition 의 hash chain 으로 잇는 EventStorage
+type EventStorage[R any] struct {
+	es.EventStorage[R]
+}
+
+// NewEventStorage | storage 에 저장하는 event 에 Hash, PrevHash 를 채운다.
+func NewEventStorage[R any](storage es.EventStorage[R]) es.EventStorage[R] {
+	return es.DecorateEventStorage[R](&EventStorage[R]{EventStorage: storage}, storage, es.EventStorageHooks[R]{})
+}
+
+func (a *EventStorage[R]) AddEvent(*es.Event[R]) error {
+	return ErrAppendOnly
+}
+
+func (a *EventStorage[R]) AppendEvent(ea *es.EventAppend[R]) (*es.Event[R], bool, error) {
+	chained := *ea
+	chained.Seal = func(e, last *es.Event[R]) (err error) {
+		if ea.Seal != nil {
+			if err = ea.Seal(e, last); err != nil {
+				return err
+			}
+		}
+		e.PrevHash = ""
+		if last != nil {
+			e.PrevHash = last.Hash
+		}
+		e.Hash, err = es.HashEvent(e)
+		return err
+	}
+	return a.EventStorage.AppendEvent(&chained)
+}
@@ -0,0 +1,104 @@
+package integrity
+
+import (
+	"context"
+	"errors"
+	es "eventsourcing"
+	"eventsourcing/estest"
+	"eventsourcing/memory"
+	"testing"
+	"time"
+)
+
+var testEventType = &es.EventType{Domain: "test", Name: "test", Version: "v1"}
+
+func appendEvents(t *testing.T, storage es.EventStorage[string], pk es.PartitionKey, n int) []*es.Event[string] {
+	t.Helper()
+	events := make([]*es.Event[string], n)
+	for i := range events {
+		request := string(pk)
+		e, _, err := storage.AppendEvent(&es.EventAppend[string]{Event: es.NewEvent[string](pk, testEventType, 0, &request)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		events[i] = e
+	}
+	return events
+}
+
+func TestHashChain(t *testing.T) {
+	tampered := estest.NewTamperedEventStorage[string](memory.NewEventStorage[string]())
+	storage := NewEventStorage[string](tampered)
+	events := appendEvents(t, storage, "pk", 4)
+	if events[0].PrevHash != "" || events[1].PrevHash != events[0].Hash || events[3].Hash == "" {
+		t.Fatalf("events = %s", es.JsonString(events))
+	}
+
+	// 같은 event 를 다시 저장해도 chain 은 그대로
+	if _, appended, err := storage.AppendEvent(&es.EventAppend[string]{Event: events[3]}); err != nil || appended {
+		t.Fatalf("retried appended = %v, %v", appended, err)
+	}
+	if err := storage.AddEvent(es.NewEvent[string]("pk", testEventType, 5, nil)); !errors.Is(err, ErrAppendOnly) {
+		t.Errorf("AddEvent err = %v", err)
+	}
+
+	report, err := Verify[string](storage, "pk")
+	if err != nil || !report.Intact() || report.Verified != 4 || report.Head != events[3].Hash {
+		t.Fatalf("report = %s, %v", es.JsonString(report), err)
+	}
+
+	// event 를 고치면 그 event 에서 끊어진다
+	edited := *events[2]
+	forged := "forged"
+	edited.Request = &forged
+	tampered.Tamper(&edited)
+	report, _ = Verify[string](storage, "pk")
+	if report.Intact() || report.Broken.EventNo != 3 || report.Broken.Reason != BrokenHash || report.Verified != 2 {
+		t.Errorf("edited report = %s", es.JsonString(report))
+	}
+}
+
+func TestCheckpointer(t *testing.T) {
+	tampered := estest.NewTamperedEventStorage[string](memory.NewEventStorage[string]())
+	storage := NewEventStorage[string](tampered)
+	signer := NewHMACSigner("k1", []byte("secret"))
+	clock := es.NewFakeClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	checkpointer := NewCheckpointer[string](storage, memory.NewHashCheckpointStorage(), signer, signer, CheckpointConfig{Clock: clock})
+
+	events := appendEvents(t, storage, "a", 3)
+	appendEvents(t, storage, "b", 2)
+	created, err := checkpointer.CheckpointAll(context.Background())
+	if err != nil || created != 2 {
+		t.Fatalf("created = %d, %v", created, err)
+	}
+	if created, _ = checkpointer.CheckpointAll(context.Background()); created != 0 {
+		t.Errorf("created without new events = %d", created)
+	}
+
+	// chain 전체를 다시 계산하면 chain 은 이어지지만 checkpoint 와 맞지 않는다
+	prev := ""
+	for _, e := range events {
+		rewritten := *e
+		forged := "forged"
+		rewritten.Request, rewritten.PrevHash = &forged, prev
+		rewritten.Hash, _ = es.HashEvent(&rewritten)
+		tampered.Tamper(&rewritten)
+		prev = rewritten.Hash
+	}
+	if report, _ := Verify[string](storage, "a"); !report.Intact() {
+		t.Fatalf("rewritten chain must be intact. %s", es.JsonString(report))
+	}
+	report, err := checkpointer.Verify("a")
+	if err != nil || report.Intact() || report.Broken.Reason != BrokenCheckpoint || report.Broken.EventNo != 3 {
+		t.Errorf("checkpoint report = %s, %v", es.JsonString(report), err)
+	}
+
+	// 다른 key 로 검증하면 서명이 맞지 않는다
+	other := NewCheckpointer[string](storage, memory.NewHashCheckpointStorage(), signer, NewHMACSigner("k1", []byte("other")), CheckpointConfig{})
+	if _, err = other.Checkpoint("b"); err != nil {
+		t.Fatal(err)
+	}
+	if report, _ = other.Verify("b"); report.Intact() || report.Broken.Reason != BrokenSignature {
+		t.Errorf("signature report = %s", es.JsonString(report))
+	}
+}
@@ -0,0 +1,149 @@
+package integrity
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	es "eventsourcing"
+	"sync"
+)
+
+// Signers
+//
+// es.Signer, es.SignatureVerifier 의 구현체
+// - HMACSigner : HMAC-SHA256, 서명하는 쪽과 검증하는 쪽이 같은 secret 을 나눠 갖는다.
+// - Ed25519Signer, Ed25519Verifier : 서명하는 서비스만 private key 를 갖고, 검증하는 쪽은 public key 만 갖는다.
+// - KeyRing : 여러 key 를 keyId 로 모아서 검증하고, 서명은 가장 최근에 Rotate 한 key 로 한다.
+
+var (
+	_ es.Signer            = &HMACSigner{}
+	_ es.SignatureVerifier = &HMACSigner{}
+	_ es.Signer            = &Ed25519Signer{}
+	_ es.SignatureVerifier = &Ed25519Signer{}
+	_ es.SignatureVerifier = &Ed25519Verifier{}
+	_ es.Signer            = &KeyRing{}
+	_ es.SignatureVerifier = &KeyRing{}
+)
+
+// HMACSigner | HMAC-SHA256 으로 서명하고 검증한다.
+type HMACSigner struct {
+	keyId  string
+	secret []byte
+}
+
+func NewHMACSigner(keyId string, secret []byte) *HMACSigner {
+	return &HMACSigner{keyId: keyId, secret: secret}
+}
+
+func (s *HMACSigner) Sign(data []byte) (string, []byte, error) {
+	return s.keyId, s.sum(data), nil
+}
+
+func (s *HMACSigner) Verify(keyId string, data, signature []byte) error {
+	if keyId != s.keyId {
+		return es.ErrUnknownKey
+	}
+	if !hmac.Equal(s.sum(data), signature) {
+		return es.ErrInvalidSignature
+	}
+	return nil
+}
+
+func (s *HMACSigner) sum(data []byte) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// Ed25519Signer | Ed25519 private key 로 서명하고, 그 public key 로 검증한다.
+type Ed25519Signer struct {
+	keyId   string
+	private ed25519.PrivateKey
+}
+
+func NewEd25519Signer(keyId string, private ed25519.PrivateKey) *Ed25519Signer {
+	return &Ed25519Signer{keyId: keyId, private: private}
+}
+
+func (s *Ed25519Signer) Sign(data []byte) (string, []byte, error) {
+	return s.keyId, ed25519.Sign(s.private, data), nil
+}
+
+func (s *Ed25519Signer) Verify(keyId string, data, signature []byte) error {
+	return NewEd25519Verifier(s.keyId, s.private.Public().(ed25519.PublicKey)).Verify(keyId, data, signature)
+}
+
+// Ed25519Verifier | Ed25519 public key 로 검증만 한다.
+type Ed25519Verifier struct {
+	keyId  string
+	public ed25519.PublicKey
+}
+
+func NewEd25519Verifier(keyId string, public ed25519.PublicKey) *Ed25519Verifier {
+	return &Ed25519Verifier{keyId: keyId, public: public}
+}
+
+func (v *Ed25519Verifier) Verify(keyId string, data, signature []byte) error {
+	if keyId != v.keyId {
+		return es.ErrUnknownKey
+	}
+	if !ed25519.Verify(v.public, data, signature) {
+		return es.ErrInvalidSignature
+	}
+	return nil
+}
+
+// KeyRing | key rotation 을 위해 여러 key 를 keyId 로 모아둔 Signer, SignatureVerifier
+// 새 key 로 Rotate 해도 이전 key 는 Retire 하기 전까지 검증에 사용한다.
+type KeyRing struct {
+	active    es.Signer
+	verifiers map[string]es.SignatureVerifier // key : keyId
+	locker    sync.RWMutex
+}
+
+func NewKeyRing() *KeyRing {
+	return &KeyRing{verifiers: make(map[string]es.SignatureVerifier)}
+}
+
+// Add | keyId 의 검증 key 를 등록한다.
+func (k *KeyRing) Add(keyId string, verifier es.SignatureVerifier) {
+	k.locker.Lock()
+	defer k.locker.Unlock()
+	k.verifiers[keyId] = verifier
+}
+
+// Rotate | 이후의 서명은 keyId 의 signer 로 한다. signer 가 SignatureVerifier 를 구현하면 검증 key 로도 등록한다.
+func (k *KeyRing) Rotate(keyId string, signer es.Signer) {
+	k.locker.Lock()
+	defer k.locker.Unlock()
+	k.active = signer
+	if verifier, ok := signer.(es.SignatureVerifier); ok {
+		k.verifiers[keyId] = verifier
+	}
+}
+
+// Retire | keyId 의 검증 key 를 지운다. 이후 그 key 로 서명한 event 는 ErrUnknownKey 로 검증에 실패한다.
+func (k *KeyRing) Retire(keyId string) {
+	k.locker.Lock()
+	defer k.locker.Unlock()
+	delete(k.verifiers, keyId)
+}
+
+func (k *KeyRing) Sign(data []byte) (string, []byte, error) {
+	k.locker.RLock()
+	defer k.locker.RUnlock()
+	if k.active == nil {
+		return "", nil, es.ErrUnknownKey
+	}
+	return k.active.Sign(data)
+}
+
+func (k *KeyRing) Verify(keyId string, data, signature []byte) error {
+	k.locker.RLock()
+	verifier, ok := k.verifiers[keyId]
+	k.locker.RUnlock()
+	if !ok {
+		return es.ErrUnknownKey
+	}
+	return verifier.Verify(keyId, data, signature)
+}
@@ -0,0 +1,83 @@
+package integrity
+
+import (
+	es "eventsourcing"
+)
+
+// BrokenReason | chain 이 끊어진 이유
+type BrokenReason string
+
+const (
+	BrokenHash       BrokenReason = "hash"       // event 의 내용이 Hash 와 맞지 않음, event 가 고쳐짐
+	BrokenPrevHash   BrokenReason = "prevHash"   // PrevHash 가 이전 event 의 Hash 와 맞지 않음, event 가 빠지거나 끼워짐
+	BrokenCheckpoint BrokenReason = "checkpoint" // 서명된 checkpoint 의 Hash 와 맞지 않음, chain 이 다시 계산됨
+	BrokenSignature  BrokenReason = "signature"  // checkpoint 의 서명이 맞지 않음
+)
+
+// BrokenLink | chain 이 처음으로 끊어진 곳
+type BrokenLink struct {
+	EventNo  int          `json:"eventNo"`
+	EventId  es.EventId   `json:"eventId,omitempty"`
+	Reason   BrokenReason `json:"reason"`
+	Expected string       `json:"expected,omitempty"` // 계산한 값
+	Actual   string       `json:"actual,omitempty"`   // 저장된 값
+}
+
+// ChainReport | partition 의 chain 검사 결과
+type ChainReport struct {
+	PartitionKey es.PartitionKey `json:"partitionKey"`
+	Verified     int             `json:"verified"`    // 끊어진 곳 전까지 검사한 event 수
+	Unchained    int             `json:"unchained"`   // chain 이 시작되기 전에 저장되어 Hash 가 없는 event 수
+	HeadEventNo  int             `json:"headEventNo"` // 검사한 마지막 event 번호
+	Head         string          `json:"head"`        // 검사한 마지막 event 의 Hash
+	Broken       *BrokenLink     `json:"broken"`      // 처음으로 끊어진 곳, 끊어지지 않았으면 nil
+	hashes       map[int]string  // eventNo 별 Hash, checkpoint 검사에 사용
+}
+
+// Intact | chain 이 끊어지지 않았는지 여부
+func (r *ChainReport) Intact() bool {
+	return r.Broken == nil
+}
+
+// Verify | pk 의 event 를 처음부터 따라가며 chain 을 검사하고, 처음으로 끊어진 곳을 알려준다.
+// chain 이 시작되기 전(decorator 를 붙이기 전)에 저장된, Hash 가 없는 앞쪽 event 는 검사하지 않는다.
+func Verify[R any](storage es.EventStorage[R], pk es.PartitionKey) (*ChainReport, error) {
+	events, err := storage.GetEvents(pk)
+	if err != nil {
+		return nil, es.NewEventStorageError(err)
+	}
+	return VerifyEvents[R](pk, events)
+}
+
+// VerifyEvents | eventNo 순서의 events 로 chain 을 검사한다.
+func VerifyEvents[R any](pk es.PartitionKey, events []*es.Event[R]) (*ChainReport, error) {
+	report := &ChainReport{PartitionKey: pk, hashes: make(map[int]string)}
+	chained := false
+	prev := ""
+	for _, e := range events {
+		if !chained && e.Hash == "" && e.PrevHash == "" {
+			report.Unchained++
+			continue
+		}
+		chained = true
+
+		if e.PrevHash != prev {
+			report.Broken = &BrokenLink{EventNo: e.EventNo, EventId: e.EventId, Reason: BrokenPrevHash, Expected: prev, Actual: e.PrevHash}
+			return report, nil
+		}
+		hash, err := es.HashEvent(e)
+		if err != nil {
+			return nil, err
+		}
+		if hash != e.Hash {
+			report.Broken = &BrokenLink{EventNo: e.EventNo, EventId: e.EventId, Reason: BrokenHash, Expected: hash, Actual: e.Hash}
+			return report, nil
+		}
+
+		prev = e.Hash
+		report.Verified++
+		report.HeadEventNo, report.Head = e.EventNo, e.Hash
+		report.hashes[e.EventNo] = e.Hash
+	}
+	return report, nil
+}
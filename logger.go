@@ -0,0 +1,121 @@
+package eventsourcing
+
+import "errors"
+
+// Structured Logging
+//
+// Manager, Processor 의 Middleware, storage decorator 는 Logger 로 구조화된 로그를 남긴다.
+// - Level 과 key-value Field 로 남기며, Field 에는 domain, pk, event type, event no 를 담는다.
+// - 기본 값은 아무것도 하지 않는 NopLogger 이며, 표준 라이브러리의 log/slog 는 NewSlogLogger 로 연결한다. (go1.21 이상, slog.go)
+
+// Level | 로그 레벨
+type Level int
+
+const (
+	LevelDebug Level = iota - 1
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Field 의 key
+const (
+	FieldKeyDomain       = "domain"
+	FieldKeyPartitionKey = "pk"
+	FieldKeyEventName    = "event_name"
+	FieldKeyEventVersion = "event_version"
+	FieldKeyEventNo      = "event_no"
+	FieldKeyEventId      = "event_id"
+	FieldKeyError        = "error"
+	FieldKeyErrorCode    = "error_code"
+)
+
+// Field | 로그에 함께 남기는 key-value
+type Field struct {
+	Key   string
+	Value any
+}
+
+// F | Field 생성
+func F(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Logger | 구조화된 로그를 남기는 인터페이스
+type Logger interface {
+	Log(level Level, msg string, fields ...Field) // 로그를 남긴다
+	With(fields ...Field) Logger                  // fields 를 항상 함께 남기는 Logger 를 만든다
+}
+
+// NopLogger | 아무것도 하지 않는 Logger, 기본 값
+type NopLogger struct{}
+
+func (NopLogger) Log(Level, string, ...Field) {}
+func (l NopLogger) With(...Field) Logger      { return l }
+
+// EventTypeFields | event type 의 domain, name, version
+// EventType.String() 은 '_' 로 이어붙여 다른 event type 과 겹칠 수 있으므로 EventTypeKey 의 값을 나눠서 남긴다.
+func EventTypeFields(et *EventType) []Field {
+	if et == nil {
+		return nil
+	}
+	return []Field{
+		F(FieldKeyDomain, string(et.Domain)),
+		F(FieldKeyEventName, string(et.Name)),
+		F(FieldKeyEventVersion, string(et.Version)),
+	}
+}
+
+// EventFields | event 의 domain, pk, event type, event no, event id
+func EventFields[R any](e *Event[R]) []Field {
+	if e == nil {
+		return nil
+	}
+	fields := []Field{
+		F(FieldKeyPartitionKey, string(e.PartitionKey)),
+		F(FieldKeyEventNo, e.EventNo),
+		F(FieldKeyEventId, string(e.EventId)),
+	}
+	if e.EventType != nil {
+		fields = append(fields, EventTypeFields(e.EventType)...)
+	}
+	return fields
+}
+
+// ErrorFields | 에러 메세지와, EventSourceError 라면 Code 와 에러가 발생한 pk, event type, event no
+func ErrorFields(err error) []Field {
+	if err == nil {
+		return nil
+	}
+	fields := []Field{F(FieldKeyError, err.Error())}
+	var e *EventSourceError
+	if !errors.As(err, &e) {
+		return fields
+	}
+	fields = append(fields, F(FieldKeyErrorCode, int(e.Code)))
+	if e.PartitionKey() != "" {
+		fields = append(fields, F(FieldKeyPartitionKey, string(e.PartitionKey())))
+	}
+	if et := e.EventType(); et != nil {
+		fields = append(fields, EventTypeFields(et)...)
+	}
+	if e.EventNo() != 0 {
+		fields = append(fields, F(FieldKeyEventNo, e.EventNo()))
+	}
+	return fields
+}
@@ -2,6 +2,7 @@ package manager
 
 import (
 	"eventsourcing"
+	"time"
 )
 
 // TODO : async 를 처리하는 도메인 추가 필요
@@ -40,7 +41,7 @@ func (e *asyncManager[S, R]) Validate(pk eventsourcing.PartitionKey, et *eventso
 	panic("implement me")
 }
 
-func (e *asyncManager[S, R]) Put(pk eventsourcing.PartitionKey, et *eventsourcing.EventType, req *R) error {
+func (e *asyncManager[S, R]) Put(pk eventsourcing.PartitionKey, et *eventsourcing.EventType, req *R, opts ...eventsourcing.PutOption) (*eventsourcing.Event[R], error) {
 	//TODO implement me
 	panic("implement me")
 }
@@ -64,3 +65,18 @@ func (e *asyncManager[S, R]) GetStateSnapshot(pk eventsourcing.PartitionKey) (*e
 	//TODO implement me
 	panic("implement me")
 }
+
+func (e *asyncManager[S, R]) GetStateAt(pk eventsourcing.PartitionKey, eventNo int) (*eventsourcing.State[S, R], error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (e *asyncManager[S, R]) GetStateAsOf(pk eventsourcing.PartitionKey, at time.Time) (*eventsourcing.State[S, R], error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (e *asyncManager[S, R]) RebuildSnapshot(pk eventsourcing.PartitionKey) error {
+	//TODO implement me
+	panic("implement me")
+}
@@ -0,0 +1,167 @@
+package manager
+
+import (
+	"context"
+	"eventsourcing"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Consistency Audit
+//
+// Process 는 state 를 제자리에서 바꾸고 snapshot 은 조금씩 갱신되므로, 잘못된 배포가 snapshot 을 조용히 망가뜨릴 수 있다.
+// Auditor 는 partition 마다 아래 두 state 를 만들어 field 단위로 비교한다.
+// - snapshot + snapshot 이후 event replay (= GetStateAt(pk, 끝))
+// - 처음부터 전체 event replay (= GetLatestState)
+// Repair 를 켜면 다른 partition 의 snapshot 을 전체 replay 결과로 덮어쓴다.
+
+// AuditConfig | Auditor 설정
+type AuditConfig struct {
+	SampleRate float64             // default 1, 0 ~ 1 사이의 비율로 partition 을 골라서 검사
+	Random     *rand.Rand          // nullable, sampling 에 사용할 random, 재현이 필요할 때 지정
+	Repair     bool                // 다른 partition 의 snapshot 을 전체 replay 결과로 덮어쓸지 여부
+	PageSize   int                 // default 100, partition 목록을 한 번에 가져오는 수
+	Clock      eventsourcing.Clock // default eventsourcing.DefaultClock, Report 의 Elapsed 측정
+}
+
+// AuditResult | partition 하나의 검사 결과
+type AuditResult struct {
+	PartitionKey eventsourcing.PartitionKey `json:"partitionKey"`
+	Diffs        []eventsourcing.Difference `json:"diffs,omitempty"` // 기대값(전체 replay)과 실제값(snapshot + replay)의 차이
+	Repaired     bool                       `json:"repaired"`        // snapshot 을 덮어썼는지 여부
+	Err          error                      `json:"-"`               // 검사나 복구 중 발생한 에러
+	Error        string                     `json:"error,omitempty"` // Err 의 메시지, json 으로 보고할 때 사용
+}
+
+// Consistent | snapshot 과 전체 replay 가 같은지 여부
+func (r *AuditResult) Consistent() bool {
+	return r.Err == nil && len(r.Diffs) == 0
+}
+
+// AuditReport | 검사 결과 모음, Results 에는 다르거나 실패한 partition 만 담는다.
+type AuditReport struct {
+	Audited      int            `json:"audited"`
+	Consistent   int            `json:"consistent"`
+	Inconsistent int            `json:"inconsistent"`
+	Repaired     int            `json:"repaired"`
+	Failed       int            `json:"failed"`
+	Results      []*AuditResult `json:"results,omitempty"`
+	Elapsed      time.Duration  `json:"elapsed"`
+}
+
+// Auditor | snapshot 과 전체 replay 의 일관성을 검사하는 작업
+type Auditor[S eventsourcing.CommonState[R], R any] struct {
+	manager Manager[S, R]
+	es      eventsourcing.EventStorage[R]
+	config  AuditConfig
+}
+
+// NewAuditor | manager 로 state 를 만들어 비교하고, es 로 partition 을 나열한다.
+func NewAuditor[S eventsourcing.CommonState[R], R any](
+	m Manager[S, R],
+	es eventsourcing.EventStorage[R],
+	config AuditConfig,
+) *Auditor[S, R] {
+	if config.SampleRate <= 0 || config.SampleRate > 1 {
+		config.SampleRate = 1
+	}
+	if config.Random == nil {
+		config.Random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	if config.PageSize <= 0 {
+		config.PageSize = 100
+	}
+	if config.Clock == nil {
+		config.Clock = eventsourcing.DefaultClock
+	}
+	return &Auditor[S, R]{
+		manager: m,
+		es:      es,
+		config:  config,
+	}
+}
+
+// Audit | 전체 partition 중 SampleRate 만큼 골라서 검사한다.
+// ctx 가 취소되면 그때까지의 Report 와 ctx 의 에러를 돌려준다.
+func (a *Auditor[S, R]) Audit(ctx context.Context) (*AuditReport, error) {
+	start := a.config.Clock.Now()
+	report := &AuditReport{}
+	cursor := ""
+	for {
+		page, next, err := a.es.ListPartitions(cursor, a.config.PageSize)
+		if err != nil {
+			report.Elapsed = a.config.Clock.Now().Sub(start)
+			return report, eventsourcing.NewEventStorageError(err)
+		}
+		for _, stat := range page {
+			if err = ctx.Err(); err != nil {
+				report.Elapsed = a.config.Clock.Now().Sub(start)
+				return report, err
+			}
+			if a.config.SampleRate < 1 && a.config.Random.Float64() >= a.config.SampleRate {
+				continue
+			}
+
+			result := a.AuditPartition(stat.PartitionKey)
+			report.Audited++
+			switch {
+			case result.Err != nil:
+				report.Failed++
+			case len(result.Diffs) == 0:
+				report.Consistent++
+			default:
+				report.Inconsistent++
+			}
+			if result.Repaired {
+				report.Repaired++
+			}
+			if !result.Consistent() {
+				report.Results = append(report.Results, result)
+			}
+		}
+		if cursor = next; cursor == "" {
+			break
+		}
+	}
+	report.Elapsed = a.config.Clock.Now().Sub(start)
+	return report, nil
+}
+
+// AuditPartition | pk 하나의 snapshot + replay 와 전체 replay 를 비교한다.
+func (a *Auditor[S, R]) AuditPartition(pk eventsourcing.PartitionKey) (result *AuditResult) {
+	result = &AuditResult{PartitionKey: pk}
+	defer func() {
+		if result.Err != nil {
+			result.Error = result.Err.Error()
+		}
+	}()
+
+	expected, err := a.manager.GetLatestState(pk)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	actual, err := a.manager.GetStateAt(pk, math.MaxInt)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Diffs, result.Err = eventsourcing.DiffJson(stateOf(expected), stateOf(actual))
+	if result.Err != nil || len(result.Diffs) == 0 || !a.config.Repair {
+		return result
+	}
+
+	if result.Err = a.manager.RebuildSnapshot(pk); result.Err == nil {
+		result.Repaired = true
+	}
+	return result
+}
+
+func stateOf[S eventsourcing.CommonState[R], R any](state *eventsourcing.State[S, R]) *S {
+	if state == nil {
+		return nil
+	}
+	return state.State()
+}
@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"errors"
+	"eventsourcing"
+	"time"
+)
+
+// Authorized Manager
+//
+// actor 하나의 요청만 받는 Manager, 모든 호출 전에 Authorizer 로 actor 의 요청을 허용할지 확인한다.
+// - Put, Validate : AuthorizePut, Put 의 event 에는 actor 를 Actor 로 기록한다. (opts 의 Actor 는 무시)
+// - GetEvents, GetLatestState, GetStateSnapshot, GetStateAt, GetStateAsOf : AuthorizeRead
+// - ApplyEvents, RebuildSnapshot : 이미 저장된 event 로 snapshot 을 만들 뿐이므로 AuthorizeRead
+// 요청마다 actor 가 다르다면 요청마다 NewAuthorizedManager 로 감싼다.
+
+// authorizedManager | actor 의 요청을 Authorizer 로 확인하는 Manager
+type authorizedManager[S eventsourcing.CommonState[R], R any] struct {
+	manager    Manager[S, R]
+	authorizer eventsourcing.Authorizer[R]
+	actor      eventsourcing.Actor
+}
+
+// NewAuthorizedManager | m 의 호출을 actor 의 요청으로 보고 authorizer 로 확인한다.
+func NewAuthorizedManager[S eventsourcing.CommonState[R], R any](
+	m Manager[S, R],
+	authorizer eventsourcing.Authorizer[R],
+	actor eventsourcing.Actor,
+) Manager[S, R] {
+	return &authorizedManager[S, R]{
+		manager:    m,
+		authorizer: authorizer,
+		actor:      actor,
+	}
+}
+
+func (a *authorizedManager[S, R]) Validate(pk eventsourcing.PartitionKey, et *eventsourcing.EventType) error {
+	if err := a.authorizePut(pk, et, nil); err != nil {
+		return err
+	}
+	return a.manager.Validate(pk, et)
+}
+
+func (a *authorizedManager[S, R]) Put(pk eventsourcing.PartitionKey, et *eventsourcing.EventType, req *R, opts ...eventsourcing.PutOption) (*eventsourcing.Event[R], error) {
+	if err := a.authorizePut(pk, et, req); err != nil {
+		return nil, err
+	}
+	opts = append(opts, eventsourcing.WithActor(a.actor)) // 마지막에 두어 다른 actor 로 기록하지 못하게 한다
+	return a.manager.Put(pk, et, req, opts...)
+}
+
+func (a *authorizedManager[S, R]) ApplyEvents(pk eventsourcing.PartitionKey) error {
+	if err := a.authorizeRead(pk); err != nil {
+		return err
+	}
+	return a.manager.ApplyEvents(pk)
+}
+
+func (a *authorizedManager[S, R]) GetEvents(pk eventsourcing.PartitionKey, eventNo int) ([]*eventsourcing.Event[R], error) {
+	if err := a.authorizeRead(pk); err != nil {
+		return nil, err
+	}
+	return a.manager.GetEvents(pk, eventNo)
+}
+
+func (a *authorizedManager[S, R]) GetLatestState(pk eventsourcing.PartitionKey) (*eventsourcing.State[S, R], error) {
+	if err := a.authorizeRead(pk); err != nil {
+		return nil, err
+	}
+	return a.manager.GetLatestState(pk)
+}
+
+func (a *authorizedManager[S, R]) GetStateSnapshot(pk eventsourcing.PartitionKey) (*eventsourcing.State[S, R], error) {
+	if err := a.authorizeRead(pk); err != nil {
+		return nil, err
+	}
+	return a.manager.GetStateSnapshot(pk)
+}
+
+func (a *authorizedManager[S, R]) GetStateAt(pk eventsourcing.PartitionKey, eventNo int) (*eventsourcing.State[S, R], error) {
+	if err := a.authorizeRead(pk); err != nil {
+		return nil, err
+	}
+	return a.manager.GetStateAt(pk, eventNo)
+}
+
+func (a *authorizedManager[S, R]) GetStateAsOf(pk eventsourcing.PartitionKey, at time.Time) (*eventsourcing.State[S, R], error) {
+	if err := a.authorizeRead(pk); err != nil {
+		return nil, err
+	}
+	return a.manager.GetStateAsOf(pk, at)
+}
+
+func (a *authorizedManager[S, R]) RebuildSnapshot(pk eventsourcing.PartitionKey) error {
+	if err := a.authorizeRead(pk); err != nil {
+		return err
+	}
+	return a.manager.RebuildSnapshot(pk)
+}
+
+// authorizePut | 허용하지 않으면 Unauthorized 에러를 돌려준다. Authorizer 의 에러가 Unauthorized 가 아니면 감싼다.
+func (a *authorizedManager[S, R]) authorizePut(pk eventsourcing.PartitionKey, et *eventsourcing.EventType, req *R) error {
+	if err := a.authorizer.AuthorizePut(a.actor, pk, et, req); err != nil {
+		if errors.Is(err, eventsourcing.ErrUnauthorized) {
+			return err
+		}
+		return eventsourcing.NewUnauthorizedError(err, a.actor, pk, et)
+	}
+	return nil
+}
+
+func (a *authorizedManager[S, R]) authorizeRead(pk eventsourcing.PartitionKey) error {
+	if err := a.authorizer.AuthorizeRead(a.actor, pk); err != nil {
+		if errors.Is(err, eventsourcing.ErrUnauthorized) {
+			return err
+		}
+		return eventsourcing.NewUnauthorizedError(err, a.actor, pk, nil)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package manager
+
+import (
+	"eventsourcing"
+	"fmt"
+)
+
+// quarantining | 실패한 event 를 격리하고 건너뛰는지 여부
+func (b *baseManager[S, R]) quarantining() bool {
+	return b.dl != nil && *b.rule.FailurePolicy == eventsourcing.QuarantineOnFailure
+}
+
+// quarantinedEventNos | pk 의 격리된 eventNo 목록, 격리하지 않는다면 nil
+func (b *baseManager[S, R]) quarantinedEventNos(pk eventsourcing.PartitionKey) (map[int]bool, error) {
+	if b.dl == nil {
+		return nil, nil
+	}
+	letters, err := b.dl.GetDeadLetters(pk)
+	if err != nil {
+		return nil, eventsourcing.NewEventStorageError(err)
+	}
+	eventNos := make(map[int]bool, len(letters))
+	for _, letter := range letters {
+		eventNos[letter.EventNo] = true
+	}
+	return eventNos, nil
+}
+
+// quarantine | 실패한 event 를 격리합니다.
+func (b *baseManager[S, R]) quarantine(pk eventsourcing.PartitionKey, e *eventsourcing.Event[R], cause error, attempts int) error {
+	err := b.dl.AddDeadLetter(&eventsourcing.DeadLetter[R]{
+		PartitionKey:  pk,
+		EventNo:       e.EventNo,
+		Event:         e,
+		Error:         cause.Error(),
+		Attempts:      attempts,
+		QuarantinedAt: b.clock.Now(),
+	})
+	if err != nil {
+		return eventsourcing.NewEventStorageError(err)
+	}
+	b.logger.Log(eventsourcing.LevelWarn, "event quarantined", append(eventsourcing.EventFields(e), eventsourcing.ErrorFields(cause)...)...)
+	return nil
+}
+
+// DeadLetterAdmin | 격리된 event 를 조회하고 처리하는 관리 도구
+// - List, ListAll : 격리된 event 와 실패 원인을 조회
+// - Correct : 격리된 event 를 대신할 정정 event 를 저장하고, 격리된 event 를 정정됨으로 기록 (격리된 event 는 계속 건너뜀)
+// - Release : Process 를 고친 뒤 격리를 해제하고 snapshot 을 다시 만듦 (다시 실패하면 다시 격리됨)
+type DeadLetterAdmin[S eventsourcing.CommonState[R], R any] struct {
+	manager Manager[S, R]
+	dl      eventsourcing.DeadLetterStorage[R]
+}
+
+// NewDeadLetterAdmin | manager 에 지정한 DeadLetterStorage 와 같은 저장소를 넣어야 한다.
+func NewDeadLetterAdmin[S eventsourcing.CommonState[R], R any](m Manager[S, R], dl eventsourcing.DeadLetterStorage[R]) *DeadLetterAdmin[S, R] {
+	return &DeadLetterAdmin[S, R]{
+		manager: m,
+		dl:      dl,
+	}
+}
+
+// List | pk 의 격리된 event 목록
+func (a *DeadLetterAdmin[S, R]) List(pk eventsourcing.PartitionKey) ([]*eventsourcing.DeadLetter[R], error) {
+	letters, err := a.dl.GetDeadLetters(pk)
+	if err != nil {
+		return nil, eventsourcing.NewEventStorageError(err)
+	}
+	return letters, nil
+}
+
+// ListAll | 전체 격리된 event 를 cursor 다음부터 limit 개 조회, 더 없으면 next 는 빈 값
+func (a *DeadLetterAdmin[S, R]) ListAll(cursor string, limit int) ([]*eventsourcing.DeadLetter[R], string, error) {
+	letters, next, err := a.dl.ListDeadLetters(cursor, limit)
+	if err != nil {
+		return nil, "", eventsourcing.NewEventStorageError(err)
+	}
+	return letters, next, nil
+}
+
+// Correct | 격리된 eventNo 를 대신할 정정 event 를 저장하고 snapshot 에 반영합니다. 정정 event 는 격리된 event 를 원인(CausationId)으로 기록합니다.
+func (a *DeadLetterAdmin[S, R]) Correct(
+	pk eventsourcing.PartitionKey,
+	eventNo int,
+	et *eventsourcing.EventType,
+	req *R,
+	opts ...eventsourcing.PutOption,
+) (*eventsourcing.Event[R], error) {
+	letter, err := a.find(pk, eventNo)
+	if err != nil {
+		return nil, err
+	}
+	correction, err := a.manager.Put(pk, et, req, append(opts, eventsourcing.CausedBy(letter.Event))...)
+	if err != nil {
+		return nil, err
+	}
+	if err = a.dl.CorrectDeadLetter(pk, eventNo, correction.EventId); err != nil {
+		return nil, eventsourcing.NewEventStorageError(err)
+	}
+	return correction, a.manager.ApplyEvents(pk)
+}
+
+// Release | 격리를 해제하고 처음부터 replay 해서 snapshot 을 다시 만듭니다.
+func (a *DeadLetterAdmin[S, R]) Release(pk eventsourcing.PartitionKey, eventNo int) error {
+	if _, err := a.find(pk, eventNo); err != nil {
+		return err
+	}
+	if err := a.dl.RemoveDeadLetter(pk, eventNo); err != nil {
+		return eventsourcing.NewEventStorageError(err)
+	}
+	return a.manager.RebuildSnapshot(pk)
+}
+
+func (a *DeadLetterAdmin[S, R]) find(pk eventsourcing.PartitionKey, eventNo int) (*eventsourcing.DeadLetter[R], error) {
+	letters, err := a.List(pk)
+	if err != nil {
+		return nil, err
+	}
+	for _, letter := range letters {
+		if letter.EventNo == eventNo {
+			return letter, nil
+		}
+	}
+	return nil, fmt.Errorf("no has dead letter. pk(%s), eventNo(%d)", pk, eventNo)
+}
@@ -0,0 +1,32 @@
+package manager
+
+import (
+	"errors"
+	"eventsourcing"
+)
+
+// finish | 작업의 계측을 끝내고, 실패했다면 로그를 남깁니다. Validate 의 실패는 요청을 거절한 것이므로 Info 로 남깁니다.
+func (b *baseManager[S, R]) finish(t *eventsourcing.Tracker, op eventsourcing.Operation, pk eventsourcing.PartitionKey, err error) {
+	t.Finish(err)
+	if err == nil {
+		return
+	}
+	level := eventsourcing.LevelError
+	if op == eventsourcing.OpValidate {
+		level = eventsourcing.LevelInfo
+	}
+	fields := []eventsourcing.Field{eventsourcing.F("operation", string(op))}
+	var esErr *eventsourcing.EventSourceError
+	if !errors.As(err, &esErr) || esErr.PartitionKey() == "" {
+		fields = append(fields, eventsourcing.F(eventsourcing.FieldKeyPartitionKey, string(pk))) // 에러에 pk 가 없을 때만 추가
+	}
+	b.logger.Log(level, string(op)+" failed", append(fields, eventsourcing.ErrorFields(err)...)...)
+}
+
+// snapshotFields | snapshot 의 pk 와 마지막 event 정보
+func (b *baseManager[S, R]) snapshotFields(pk eventsourcing.PartitionKey, state *eventsourcing.State[S, R]) []eventsourcing.Field {
+	if last := (*state.State()).GetLastEvent(); last != nil {
+		return eventsourcing.EventFields(last)
+	}
+	return []eventsourcing.Field{eventsourcing.F(eventsourcing.FieldKeyPartitionKey, string(pk))}
+}
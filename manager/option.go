@@ -0,0 +1,64 @@
+package manager
+
+import "eventsourcing"
+
+// Option | Manager 생성 시 기본 구성 요소를 바꾸는 옵션
+type Option[S eventsourcing.CommonState[R], R any] func(b *baseManager[S, R])
+
+// WithClock | Event 생성과 Rule 판단에 사용할 Clock 을 지정한다. default eventsourcing.DefaultClock
+func WithClock[S eventsourcing.CommonState[R], R any](clock eventsourcing.Clock) Option[S, R] {
+	return func(b *baseManager[S, R]) {
+		b.clock = clock
+	}
+}
+
+// WithIdGenerator | Event 생성에 사용할 IdGenerator 를 지정한다. default eventsourcing.DefaultIdGenerator
+func WithIdGenerator[S eventsourcing.CommonState[R], R any](gen eventsourcing.IdGenerator) Option[S, R] {
+	return func(b *baseManager[S, R]) {
+		b.idGenerator = gen
+	}
+}
+
+// WithDeadLetterStorage | Rule.FailurePolicy 가 QuarantineOnFailure 일 때 실패한 event 를 격리할 저장소를 지정한다.
+// 지정하지 않으면 QuarantineOnFailure 여도 HaltOnFailure 처럼 실패한 event 에서 멈춘다.
+func WithDeadLetterStorage[S eventsourcing.CommonState[R], R any](dl eventsourcing.DeadLetterStorage[R]) Option[S, R] {
+	return func(b *baseManager[S, R]) {
+		b.dl = dl
+	}
+}
+
+// WithInstrumentation | 작업을 계측할 Instrumentation 을 지정한다. default eventsourcing.NopInstrumentation
+func WithInstrumentation[S eventsourcing.CommonState[R], R any](inst eventsourcing.Instrumentation) Option[S, R] {
+	return func(b *baseManager[S, R]) {
+		b.inst = inst
+	}
+}
+
+// WithLogger | 작업의 로그를 남길 Logger 를 지정한다. default eventsourcing.NopLogger
+func WithLogger[S eventsourcing.CommonState[R], R any](logger eventsourcing.Logger) Option[S, R] {
+	return func(b *baseManager[S, R]) {
+		b.logger = logger
+	}
+}
+
+// WithSigner | Put 에서 event 에 서명할 Signer 를 지정한다. 지정하지 않으면 서명하지 않는다.
+func WithSigner[S eventsourcing.CommonState[R], R any](signer eventsourcing.Signer) Option[S, R] {
+	return func(b *baseManager[S, R]) {
+		b.signer = signer
+	}
+}
+
+// WithSignatureVerifier | replay, 조회 중 event 의 서명을 검증할 SignatureVerifier 를 지정한다. 지정하지 않으면 검증하지 않는다.
+// 서명이 맞지 않는 event 는 Rule.SignaturePolicy 를 따른다.
+func WithSignatureVerifier[S eventsourcing.CommonState[R], R any](verifier eventsourcing.SignatureVerifier) Option[S, R] {
+	return func(b *baseManager[S, R]) {
+		b.verifier = verifier
+	}
+}
+
+// WithRegistry | Put 에서 registry 에 등록되지 않은 EventType 을 ErrUnregisteredEventType 으로 거절한다. 지정하지 않으면 검사하지 않는다.
+func WithRegistry[S eventsourcing.CommonState[R], R any](registry *eventsourcing.EventTypeRegistry) Option[S, R] {
+	return func(b *baseManager[S, R]) {
+		b.registry = registry
+	}
+}
@@ -0,0 +1,196 @@
+package manager
+
+import (
+	"context"
+	"eventsourcing"
+	"sync"
+	"time"
+)
+
+// Snapshot Rebuild
+//
+// Process 의 bug 를 고친 뒤에는 모든 partition 의 snapshot 을 다시 만들어야 한다.
+// Rebuilder 는 EventStorage.ListPartitions 로 partition 을 나열하고, worker pool 로 partition 마다 Manager.RebuildSnapshot 을 실행한다.
+// - Filter 로 대상 partition 을 좁힐 수 있다. (domain, 특정 시간 이후 변경된 partition)
+// - Checkpoint 를 지정하면 끝난 partition 을 기록하므로, 중단된 작업을 같은 jobId 로 다시 실행하면 남은 partition 만 rebuild 한다.
+// - 실패한 partition 은 작업을 멈추지 않고 Report 에 모아서 돌려준다.
+
+// RebuildFilter | rebuild 대상 partition 을 고르는 조건, 비어있는 조건은 검사하지 않는다.
+type RebuildFilter struct {
+	Domain       eventsourcing.Domain // partition 의 마지막 event 의 domain
+	ChangedSince *time.Time           // partition 의 마지막 event 가 이 시간 이후(포함)인 것만
+}
+
+// Match | partition 이 조건에 맞는지 확인한다.
+func (f RebuildFilter) Match(stat *eventsourcing.PartitionStat) bool {
+	if f.Domain != "" && (stat.LastEventType == nil || stat.LastEventType.Domain != f.Domain) {
+		return false
+	}
+	if f.ChangedSince != nil && stat.LastEventAt.Before(*f.ChangedSince) {
+		return false
+	}
+	return true
+}
+
+// RebuildConfig | Rebuilder 설정
+type RebuildConfig struct {
+	Workers    int                                    // default 4, 동시에 rebuild 하는 partition 수
+	PageSize   int                                    // default 100, partition 목록을 한 번에 가져오는 수
+	Checkpoint eventsourcing.RebuildCheckpointStorage // nullable, 끝난 partition 을 기록하는 저장소
+	OnProgress func(progress RebuildProgress)         // nullable, partition 하나가 끝날 때 마다 호출
+	Clock      eventsourcing.Clock                    // default eventsourcing.DefaultClock, Report 의 Elapsed 측정
+}
+
+// RebuildProgress | rebuild 진행 상황
+type RebuildProgress struct {
+	Listed   int `json:"listed"`   // 나열한 partition 수
+	Rebuilt  int `json:"rebuilt"`  // rebuild 한 partition 수
+	Resumed  int `json:"resumed"`  // 이전 실행에서 이미 끝나서 건너뛴 partition 수
+	Filtered int `json:"filtered"` // 조건에 맞지 않아 건너뛴 partition 수
+	Failed   int `json:"failed"`   // 실패한 partition 수
+}
+
+// RebuildFailure | rebuild 에 실패한 partition 과 에러
+type RebuildFailure struct {
+	PartitionKey eventsourcing.PartitionKey
+	Err          error
+}
+
+// RebuildReport | rebuild 결과
+type RebuildReport struct {
+	RebuildProgress
+	Failures []RebuildFailure
+	Elapsed  time.Duration
+}
+
+// Rebuilder | 여러 partition 의 snapshot 을 병렬로 다시 만드는 작업
+type Rebuilder[S eventsourcing.CommonState[R], R any] struct {
+	manager Manager[S, R]
+	es      eventsourcing.EventStorage[R]
+	config  RebuildConfig
+}
+
+// NewRebuilder | manager 로 snapshot 을 다시 만들고, es 로 partition 을 나열한다.
+func NewRebuilder[S eventsourcing.CommonState[R], R any](
+	m Manager[S, R],
+	es eventsourcing.EventStorage[R],
+	config RebuildConfig,
+) *Rebuilder[S, R] {
+	if config.Workers <= 0 {
+		config.Workers = 4
+	}
+	if config.PageSize <= 0 {
+		config.PageSize = 100
+	}
+	if config.Clock == nil {
+		config.Clock = eventsourcing.DefaultClock
+	}
+	return &Rebuilder[S, R]{
+		manager: m,
+		es:      es,
+		config:  config,
+	}
+}
+
+// Rebuild | filter 에 맞는 partition 의 snapshot 을 다시 만든다.
+// ctx 가 취소되면 새 partition 을 시작하지 않고, 그때까지의 Report 와 ctx 의 에러를 돌려준다.
+func (r *Rebuilder[S, R]) Rebuild(ctx context.Context, jobId string, filter RebuildFilter) (*RebuildReport, error) {
+	start := r.config.Clock.Now()
+	report := &RebuildReport{}
+	var locker sync.Mutex
+	record := func(update func(report *RebuildReport)) {
+		locker.Lock()
+		defer locker.Unlock()
+		update(report)
+		if r.config.OnProgress != nil {
+			r.config.OnProgress(report.RebuildProgress)
+		}
+	}
+
+	// worker pool
+	partitions := make(chan *eventsourcing.PartitionStat)
+	var wg sync.WaitGroup
+	for i := 0; i < r.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for stat := range partitions {
+				r.rebuild(jobId, stat, filter, record)
+			}
+		}()
+	}
+
+	// partition 을 page 단위로 나열해서 worker 에 넘긴다
+	var err error
+	cursor := ""
+list:
+	for {
+		var page []*eventsourcing.PartitionStat
+		page, cursor, err = r.es.ListPartitions(cursor, r.config.PageSize)
+		if err != nil {
+			err = eventsourcing.NewEventStorageError(err)
+			break
+		}
+		for _, stat := range page {
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+				break list
+			case partitions <- stat:
+				record(func(report *RebuildReport) { report.Listed++ })
+			}
+		}
+		if cursor == "" {
+			break
+		}
+	}
+	close(partitions)
+	wg.Wait()
+
+	report.Elapsed = r.config.Clock.Now().Sub(start)
+	return report, err
+}
+
+func (r *Rebuilder[S, R]) rebuild(
+	jobId string,
+	stat *eventsourcing.PartitionStat,
+	filter RebuildFilter,
+	record func(update func(report *RebuildReport)),
+) {
+	pk := stat.PartitionKey
+	fail := func(err error) {
+		record(func(report *RebuildReport) {
+			report.Failed++
+			report.Failures = append(report.Failures, RebuildFailure{PartitionKey: pk, Err: err})
+		})
+	}
+
+	if r.config.Checkpoint != nil {
+		done, err := r.config.Checkpoint.IsRebuilt(jobId, pk)
+		if err != nil {
+			fail(err)
+			return
+		}
+		if done {
+			record(func(report *RebuildReport) { report.Resumed++ })
+			return
+		}
+	}
+
+	if !filter.Match(stat) {
+		record(func(report *RebuildReport) { report.Filtered++ })
+		return
+	}
+
+	if err := r.manager.RebuildSnapshot(pk); err != nil {
+		fail(err)
+		return
+	}
+	if r.config.Checkpoint != nil {
+		if err := r.config.Checkpoint.MarkRebuilt(jobId, pk); err != nil {
+			fail(err)
+			return
+		}
+	}
+	record(func(report *RebuildReport) { report.Rebuilt++ })
+}
@@ -1,6 +1,9 @@
 package manager
 
-import "eventsourcing"
+import (
+	"eventsourcing"
+	"time"
+)
 
 // TODO 매니저를 역할별로 더 나누어야 할 듯
 // 예상
@@ -11,21 +14,32 @@ import "eventsourcing"
 // querier
 
 type Manager[S eventsourcing.CommonState[R], R any] interface {
-	Validate(pk eventsourcing.PartitionKey, et *eventsourcing.EventType) error               // 이벤트를 실행해도 되는지 유효성 검사를 한다.
-	Put(pk eventsourcing.PartitionKey, et *eventsourcing.EventType, req *R) error            // 이벤트를 저장한다.
-	ApplyEvents(pk eventsourcing.PartitionKey) error                                         // 이벤트를 적용한다.
-	GetEvents(pk eventsourcing.PartitionKey, eventNo int) ([]*eventsourcing.Event[R], error) // eventNo 보다 큰 이벤트 리스트를 가져온다.
-	GetLatestState(pk eventsourcing.PartitionKey) (*eventsourcing.State[S, R], error)        // 이벤트로 리플레이한 최신 스테이트를 가져온다.
-	GetStateSnapshot(pk eventsourcing.PartitionKey) (*eventsourcing.State[S, R], error)      // 스냅샷의 스테이트를 가져온다.
+	Validate(pk eventsourcing.PartitionKey, et *eventsourcing.EventType) error                                                                // 이벤트를 실행해도 되는지 유효성 검사를 한다.
+	Put(pk eventsourcing.PartitionKey, et *eventsourcing.EventType, req *R, opts ...eventsourcing.PutOption) (*eventsourcing.Event[R], error) // 이벤트를 저장하고 저장된 이벤트를 돌려준다. opts 로 metadata, 멱등키를 함께 기록한다.
+	ApplyEvents(pk eventsourcing.PartitionKey) error                                                                                          // 이벤트를 적용한다.
+	GetEvents(pk eventsourcing.PartitionKey, eventNo int) ([]*eventsourcing.Event[R], error)                                                  // eventNo 보다 큰 이벤트 리스트를 가져온다.
+	GetLatestState(pk eventsourcing.PartitionKey) (*eventsourcing.State[S, R], error)                                                         // 이벤트로 리플레이한 최신 스테이트를 가져온다.
+	GetStateSnapshot(pk eventsourcing.PartitionKey) (*eventsourcing.State[S, R], error)                                                       // 스냅샷의 스테이트를 가져온다.
+	GetStateAt(pk eventsourcing.PartitionKey, eventNo int) (*eventsourcing.State[S, R], error)                                                // eventNo 까지 리플레이한 스테이트를 가져온다.
+	GetStateAsOf(pk eventsourcing.PartitionKey, at time.Time) (*eventsourcing.State[S, R], error)                                             // at 시점까지 리플레이한 스테이트를 가져온다.
+	RebuildSnapshot(pk eventsourcing.PartitionKey) error                                                                                      // 처음부터 리플레이해서 스냅샷을 다시 만든다.
 }
 
 // baseManager | 가장 기본적인 이벤트 소싱 매니저, 메세지 스트림을 사용하지 않는다.
 type baseManager[S eventsourcing.CommonState[R], R any] struct {
-	processor *eventsourcing.Processor[S, R]
-	validator *eventsourcing.Validator[S, R]
-	es        eventsourcing.EventStorage[R]
-	ss        eventsourcing.StateSnapshotStorage[S, R]
-	rule      *eventsourcing.Rule
+	processor   *eventsourcing.Processor[S, R]
+	validator   *eventsourcing.Validator[S, R]
+	es          eventsourcing.EventStorage[R]
+	ss          eventsourcing.StateSnapshotStorage[S, R]
+	rule        *eventsourcing.Rule
+	clock       eventsourcing.Clock
+	idGenerator eventsourcing.IdGenerator
+	dl          eventsourcing.DeadLetterStorage[R] // nullable, Rule.FailurePolicy 가 QuarantineOnFailure 일 때 실패한 event 를 격리하는 저장소
+	inst        eventsourcing.Instrumentation      // 작업을 계측
+	logger      eventsourcing.Logger               // 작업의 로그를 남김
+	signer      eventsourcing.Signer               // nullable, Put 에서 event 에 서명
+	verifier    eventsourcing.SignatureVerifier    // nullable, replay, 조회 중 event 의 서명을 검증
+	registry    *eventsourcing.EventTypeRegistry   // nullable, Put 에서 등록된 EventType 인지 검사
 }
 
 // NewBaseManager | 기본적인 매니저를 생성한다. 아래의 규칙을 따름
@@ -39,37 +53,147 @@ type baseManager[S eventsourcing.CommonState[R], R any] struct {
 // 4. GetEvents : EventStorage 에서 pk 로 이벤트를 조회
 //
 // 5. GetStateSnapshot : StateSnapshotStorage + EventStorage 를 합쳐서 최신 State 를 조회
+//
+// 6. GetStateAt, GetStateAsOf : 특정 eventNo, 시점까지만 replay 하여 과거의 State 를 조회
+//
+// 7. RebuildSnapshot : 기존 snapshot 을 무시하고 처음부터 replay 하여 snapshot 을 덮어씀
+//
+// opts 로 Clock, IdGenerator 등을 바꿀 수 있다.
 func NewBaseManager[S eventsourcing.CommonState[R], R any](
 	rule *eventsourcing.Rule,
 	c *eventsourcing.Processor[S, R],
 	v *eventsourcing.Validator[S, R],
 	es eventsourcing.EventStorage[R],
 	ss eventsourcing.StateSnapshotStorage[S, R],
+	opts ...Option[S, R],
 ) Manager[S, R] {
 	r := eventsourcing.NewDefaultRule()
 	r.Merge(rule)
-	return &baseManager[S, R]{
-		processor: c,
-		validator: v,
-		es:        es,
-		ss:        ss,
-		rule:      r,
+	b := &baseManager[S, R]{
+		processor:   c,
+		validator:   v,
+		es:          es,
+		ss:          ss,
+		rule:        r,
+		clock:       eventsourcing.DefaultClock,
+		idGenerator: eventsourcing.DefaultIdGenerator,
+		inst:        eventsourcing.NopInstrumentation{},
+		logger:      eventsourcing.NopLogger{},
+	}
+	for _, opt := range opts {
+		opt(b)
 	}
+	return b
 }
 
-func (b *baseManager[S, R]) replay(pk eventsourcing.PartitionKey, current *eventsourcing.State[S, R], events []*eventsourcing.Event[R]) (state *eventsourcing.State[S, R], err error) {
+// replay | current 의 복사본에 events 를 적용합니다. replay 가 중간에 실패해도 current 는 바뀌지 않습니다.
+// 실패한 event 는 Rule.FailureRetries 만큼 다시 시도하고, Rule.FailurePolicy 에 따라 멈추거나 격리한 뒤 건너뜁니다.
+// 적용에 성공한 event 만 replay 한 event 로 세어 parent 에도 더합니다.
+func (b *baseManager[S, R]) replay(pk eventsourcing.PartitionKey, parent *eventsourcing.Tracker, current *eventsourcing.State[S, R], events []*eventsourcing.Event[R]) (state *eventsourcing.State[S, R], err error) {
+	if len(events) == 0 {
+		return current, nil
+	}
+	t := eventsourcing.StartTracking(b.inst, eventsourcing.OpReplay, pk, nil)
+	defer func() { t.Finish(err) }()
+	t.SetDomain(events[0].Domain)
+	parent.SetDomain(events[0].Domain)
+
+	current, err = current.Clone()
+	if err != nil {
+		return nil, err
+	}
+	quarantined, err := b.quarantinedEventNos(pk)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, e := range events {
-		cmd, ok := b.processor.GetProcess(*e.EventType)
-		if !ok {
-			return nil, eventsourcing.NewNoHasCommandError(pk, e.EventType)
+		if quarantined[e.EventNo] {
+			continue // 격리된 event 는 건너뜀
 		}
-		current = cmd(current, e)
+		if err = b.verify(pk, e); err != nil {
+			return nil, err
+		}
+		if e.Shredded() {
+			switch *b.rule.ShreddedPolicy {
+			case eventsourcing.SkipShredded:
+				eventsourcing.RecordLastEvent(current, e)
+				b.logger.Log(eventsourcing.LevelDebug, "shredded event skipped", eventsourcing.EventFields(e)...)
+				continue
+			case eventsourcing.HaltOnShredded:
+				return nil, eventsourcing.NewCommandError(eventsourcing.ErrShreddedEvent, pk, e)
+			}
+		}
+		next, attempts, err := b.apply(pk, current, e)
+		if err != nil {
+			if !b.quarantining() {
+				return nil, err
+			}
+			if err = b.quarantine(pk, e, err, attempts); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		current = next
+		t.AddEventsReplayed(1)
+		parent.AddEventsReplayed(1)
 	}
 	return current, nil
 }
 
-// Validate | 이벤트를 적용할 수 있는지 Validating
+// apply | event 하나를 적용합니다. 실패하면 Rule.FailureRetries 만큼 다시 시도합니다.
+// 다시 시도하거나 격리할 수 있다면, 실패한 Process 가 current 를 바꿔두지 않도록 복사본에 적용합니다.
+func (b *baseManager[S, R]) apply(pk eventsourcing.PartitionKey, current *eventsourcing.State[S, R], e *eventsourcing.Event[R]) (state *eventsourcing.State[S, R], attempts int, err error) {
+	cmd, ok := b.processor.GetProcess(*e.EventType)
+	if !ok {
+		return nil, 1, eventsourcing.NewNoHasCommandError(pk, e.EventType)
+	}
+
+	retries := *b.rule.FailureRetries
+	isolate := retries > 0 || b.quarantining()
+	for attempts = 1; ; attempts++ {
+		state = current
+		if isolate {
+			if state, err = current.Clone(); err != nil {
+				return nil, attempts, err
+			}
+		}
+		state, err = b.process(cmd, state, e)
+		if err == nil {
+			return state, attempts, nil
+		}
+		if attempts > retries {
+			return nil, attempts, eventsourcing.NewCommandError(err, pk, e)
+		}
+	}
+}
+
+// process | Process 를 수행합니다. Process 의 panic 은 에러로 돌려줍니다.
+func (b *baseManager[S, R]) process(cmd eventsourcing.Process[S, R], state *eventsourcing.State[S, R], e *eventsourcing.Event[R]) (next *eventsourcing.State[S, R], err error) {
+	defer eventsourcing.HandleError(&err)
+	return cmd(state, e)
+}
+
+// verify | event 의 서명을 검증합니다. 맞지 않으면 Rule.SignaturePolicy 에 따라 에러를 돌려주거나 로그만 남깁니다.
+func (b *baseManager[S, R]) verify(pk eventsourcing.PartitionKey, e *eventsourcing.Event[R]) error {
+	if b.verifier == nil || *b.rule.SignaturePolicy == eventsourcing.IgnoreSignature {
+		return nil
+	}
+	err := eventsourcing.VerifyEvent(b.verifier, e)
+	if err == nil {
+		return nil
+	}
+	if *b.rule.SignaturePolicy == eventsourcing.WarnInvalidSignature {
+		b.logger.Log(eventsourcing.LevelWarn, "invalid event signature", append(eventsourcing.EventFields(e), eventsourcing.ErrorFields(err)...)...)
+		return nil
+	}
+	return eventsourcing.NewCommandError(err, pk, e)
+}
+
+// Validate | 이벤트를 적용할 수 있는지 Validating, snapshot 에 이후 event 까지 replay 한 최신 state 로 검사합니다.
 func (b *baseManager[S, R]) Validate(pk eventsourcing.PartitionKey, et *eventsourcing.EventType) (err error) {
+	t := eventsourcing.StartTracking(b.inst, eventsourcing.OpValidate, pk, et)
+	defer func() { b.finish(t, eventsourcing.OpValidate, pk, err) }()
 	defer eventsourcing.HandleError(&err)
 
 	// get validates
@@ -78,18 +202,12 @@ func (b *baseManager[S, R]) Validate(pk eventsourcing.PartitionKey, et *eventsou
 		return nil // validate 가 지정되지 않았으므로 검사 없이 끝
 	}
 
-	// get snapshot
-	snapshot, err := b.GetStateSnapshot(pk)
+	// get the latest state
+	// snapshot 은 Rule 의 term 만큼 늦게 저장되므로, snapshot 이후의 event 까지 replay 한 state 로 검사한다
+	snapshot, err := b.getStateUntil(pk, t, func(int, time.Time) bool { return true })
 	if err != nil {
 		return err
 	}
-	if snapshot == nil {
-		err = b.ApplyEvents(pk) // 스냅샷이 없으면 최신으로 업데이트 한다
-		if err != nil {
-			return err
-		}
-		snapshot, err = b.GetStateSnapshot(pk) // 다시 가져옴
-	}
 
 	// get the latest event
 	latest, err := b.es.GetLastEvent(pk)
@@ -105,26 +223,57 @@ func (b *baseManager[S, R]) Validate(pk eventsourcing.PartitionKey, et *eventsou
 	return nil
 }
 
-// Put | 이벤트를 저장합니다.
-func (b *baseManager[S, R]) Put(pk eventsourcing.PartitionKey, et *eventsourcing.EventType, req *R) (err error) {
+// Put | 이벤트를 저장합니다. opts 로 CorrelationId, CausationId, Actor, Metadata 를 함께 기록합니다.
+// 멱등키가 지정되었고 Rule.IdempotencyWindow 안에 같은 키로 저장된 이벤트가 있다면, 새로 저장하지 않고 그 이벤트를 돌려줍니다.
+// 같은 멱등키를 다른 EventType 이나 Request 로 다시 쓰면 ErrIdempotencyKeyReused 를 돌려줍니다.
+// WithRegistry 로 registry 를 지정했다면 등록되지 않은 EventType 은 ErrUnregisteredEventType 으로 거절합니다.
+func (b *baseManager[S, R]) Put(pk eventsourcing.PartitionKey, et *eventsourcing.EventType, req *R, opts ...eventsourcing.PutOption) (event *eventsourcing.Event[R], err error) {
+	t := eventsourcing.StartTracking(b.inst, eventsourcing.OpPut, pk, et)
+	defer func() { b.finish(t, eventsourcing.OpPut, pk, err) }()
 	defer eventsourcing.HandleError(&err)
 
-	// event 생성 및 command 실행
-	var no int
-	no, err = b.es.IncreaseEventNo(pk) // 이벤트 번호를 받아옴
-	if err != nil {
-		return eventsourcing.NewDispenseEventNoError(err, pk)
+	if b.registry != nil {
+		if err = b.registry.Check(et); err != nil {
+			return nil, err
+		}
 	}
-	event := eventsourcing.NewEvent[R](pk, et, no, req) // 이벤트 생성
-	err = b.es.AddEvent(event)                          // 이벤트를 EventStorage 에 추가
+
+	// event 생성 및 저장, 번호 발급과 멱등키 확인은 저장소가 저장하면서 함께 한다
+	o := eventsourcing.NewPutOptions(opts...)
+	event = eventsourcing.NewEventWith[R](b.idGenerator, b.clock, pk, et, 0, req) // 이벤트 생성
+	eventsourcing.ApplyPutOptions(event, o)                                       // metadata 기록
+	ea := &eventsourcing.EventAppend[R]{Event: event, Seal: b.seal}
+	if o.IdempotencyKey != "" {
+		ea.IdempotencySince = b.clock.Now().Add(-*b.rule.IdempotencyWindow)
+	}
+	stored, appended, err := b.es.AppendEvent(ea)
 	if err != nil {
-		return eventsourcing.NewEventStorageError(err)
+		return nil, eventsourcing.NewEventStorageError(err)
 	}
-	return nil
+	if !appended && stored.EventId != event.EventId {
+		// 이미 저장된 요청이므로 처음 저장된 이벤트를 돌려준다
+		if err = eventsourcing.MatchIdempotentEvent(stored, et, req); err != nil {
+			return nil, err
+		}
+		b.logger.Log(eventsourcing.LevelDebug, "idempotent put hit", eventsourcing.EventFields(stored)...)
+		return stored, nil
+	}
+	b.logger.Log(eventsourcing.LevelDebug, "event put", eventsourcing.EventFields(stored)...)
+	return stored, nil
+}
+
+// seal | 저장할 event 에 서명합니다. 번호가 발급된 뒤에 서명해야 EventNo 도 서명에 포함됩니다.
+func (b *baseManager[S, R]) seal(e, _ *eventsourcing.Event[R]) error {
+	if b.signer == nil {
+		return nil
+	}
+	return eventsourcing.SignEvent(b.signer, e)
 }
 
 // ApplyEvents | pk 에 쌓여있는 이벤트 들을 적용합니다. => snapshot 에 반영
 func (b *baseManager[S, R]) ApplyEvents(pk eventsourcing.PartitionKey) (err error) {
+	t := eventsourcing.StartTracking(b.inst, eventsourcing.OpApplyEvents, pk, nil)
+	defer func() { b.finish(t, eventsourcing.OpApplyEvents, pk, err) }()
 	defer eventsourcing.HandleError(&err)
 
 	/**
@@ -136,20 +285,26 @@ func (b *baseManager[S, R]) ApplyEvents(pk eventsourcing.PartitionKey) (err erro
 	case 1. events 가 없다면 이미 스냅샷이 최신 상태
 	case 2. events 와 state 로 replay
 
-	3. replay 된 state 를 snapshot 에 저장
+	3. Rule 에 따라 snapshot 저장이 필요하면 replay 된 state 를 snapshot 에 저장
 	*/
 	// 스냅샷이 있는지 조회, 없다면 만들어 주어야 함
 
 	state, err := b.GetStateSnapshot(pk)
+	if err != nil {
+		return err
+	}
+	t.SetSnapshotHit(state != nil)
 
 	// replay 할 event 리스트를 만듦
 	var events []*eventsourcing.Event[R]
 	var eventNo int
+	var eventAt time.Time
 	if state != nil { // 스냅샷이 존재하는 경우, snapshot 이후의 events 만 가져온다
-		eventNo = (*state.State()).GetLastEvent().EventNo
+		last := (*state.State()).GetLastEvent()
+		eventNo, eventAt = last.EventNo, last.EventAt
 	}
 
-	events, err = b.GetEvents(pk, eventNo)
+	events, err = b.events(pk, eventNo) // 서명은 replay 에서 검증
 	if err != nil {
 		return
 	}
@@ -157,25 +312,46 @@ func (b *baseManager[S, R]) ApplyEvents(pk eventsourcing.PartitionKey) (err erro
 	if len(events) == 0 {
 		return nil // 이미 스냅샷이 최신이므로 리턴
 	}
+	if !b.rule.NeedSnapshot(b.clock, eventNo, eventAt, events[len(events)-1].EventNo) {
+		return nil // 아직 snapshot 을 갱신할 term 이 되지 않음
+	}
 
 	// replay events, event 로 현재 state 를 만든다
-	state, err = b.replay(pk, state, events)
+	state, err = b.replay(pk, t, state, events)
 	if err != nil {
 		return err
 	}
+	if state == nil {
+		return nil // 적용된 event 없이 모두 격리되거나 건너뜀
+	}
 
 	// snapshot 에 저장
 	err = b.ss.SaveSnapshot(pk, state)
 	if err != nil {
 		return eventsourcing.NewSnapshotStorageError(err)
 	}
+	b.logger.Log(eventsourcing.LevelDebug, "snapshot saved", b.snapshotFields(pk, state)...)
 	return nil
 }
 
-// GetEvents | pk 의 event 리스트를 가져옵니다
+// GetEvents | pk 의 event 리스트를 가져옵니다. SignatureVerifier 가 있으면 서명을 검증합니다.
 func (b *baseManager[S, R]) GetEvents(pk eventsourcing.PartitionKey, afterEventNo int) (events []*eventsourcing.Event[R], err error) {
 	defer eventsourcing.HandleError(&err)
 
+	events, err = b.events(pk, afterEventNo)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		if err = b.verify(pk, e); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// events | pk 의 afterEventNo 이후 event 리스트를 가져옵니다.
+func (b *baseManager[S, R]) events(pk eventsourcing.PartitionKey, afterEventNo int) (events []*eventsourcing.Event[R], err error) {
 	if afterEventNo == 0 {
 		events, err = b.es.GetEvents(pk)
 		if err != nil {
@@ -192,6 +368,8 @@ func (b *baseManager[S, R]) GetEvents(pk eventsourcing.PartitionKey, afterEventN
 
 // GetLatestState | pk 의 이벤트를 replay 해서 최신 state 를 만듭니다.
 func (b *baseManager[S, R]) GetLatestState(pk eventsourcing.PartitionKey) (state *eventsourcing.State[S, R], err error) {
+	t := eventsourcing.StartTracking(b.inst, eventsourcing.OpGetState, pk, nil)
+	defer func() { b.finish(t, eventsourcing.OpGetState, pk, err) }()
 	defer eventsourcing.HandleError(&err)
 
 	var events []*eventsourcing.Event[R]
@@ -199,7 +377,7 @@ func (b *baseManager[S, R]) GetLatestState(pk eventsourcing.PartitionKey) (state
 	if err != nil {
 		return nil, eventsourcing.NewEventStorageError(err)
 	}
-	state, err = b.replay(pk, nil, events)
+	state, err = b.replay(pk, t, nil, events)
 	if err != nil {
 		return nil, err
 	}
@@ -216,3 +394,104 @@ func (b *baseManager[S, R]) GetStateSnapshot(pk eventsourcing.PartitionKey) (sta
 	}
 	return
 }
+
+// RebuildSnapshot | pk 의 이벤트를 처음부터 replay 해서 snapshot 을 덮어씁니다. Process 를 고친 뒤 snapshot 을 다시 만들 때 사용합니다.
+func (b *baseManager[S, R]) RebuildSnapshot(pk eventsourcing.PartitionKey) (err error) {
+	t := eventsourcing.StartTracking(b.inst, eventsourcing.OpRebuildSnapshot, pk, nil)
+	defer func() { b.finish(t, eventsourcing.OpRebuildSnapshot, pk, err) }()
+	defer eventsourcing.HandleError(&err)
+
+	state, err := b.GetLatestState(pk)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return nil // 이벤트가 없으므로 만들 snapshot 이 없음
+	}
+	err = b.ss.SaveSnapshot(pk, state)
+	if err != nil {
+		return eventsourcing.NewSnapshotStorageError(err)
+	}
+	b.logger.Log(eventsourcing.LevelInfo, "snapshot rebuilt", b.snapshotFields(pk, state)...)
+	return nil
+}
+
+// GetStateAt | pk 의 eventNo 까지 이벤트를 replay 한 state 를 만듭니다. eventNo 까지의 이벤트가 없으면 nil 을 돌려줍니다.
+func (b *baseManager[S, R]) GetStateAt(pk eventsourcing.PartitionKey, eventNo int) (state *eventsourcing.State[S, R], err error) {
+	t := eventsourcing.StartTracking(b.inst, eventsourcing.OpGetState, pk, nil)
+	defer func() { b.finish(t, eventsourcing.OpGetState, pk, err) }()
+	defer eventsourcing.HandleError(&err)
+
+	return b.getStateUntil(pk, t, func(no int, _ time.Time) bool {
+		return no <= eventNo
+	})
+}
+
+// GetStateAsOf | pk 의 at 시점까지(at 포함) 발생한 이벤트를 replay 한 state 를 만듭니다. at 이전의 이벤트가 없으면 nil 을 돌려줍니다.
+func (b *baseManager[S, R]) GetStateAsOf(pk eventsourcing.PartitionKey, at time.Time) (state *eventsourcing.State[S, R], err error) {
+	t := eventsourcing.StartTracking(b.inst, eventsourcing.OpGetState, pk, nil)
+	defer func() { b.finish(t, eventsourcing.OpGetState, pk, err) }()
+	defer eventsourcing.HandleError(&err)
+
+	return b.getStateUntil(pk, t, func(_ int, eventAt time.Time) bool {
+		return !eventAt.After(at)
+	})
+}
+
+// getStateUntil | within 을 만족하는 이벤트까지만 replay 합니다.
+// 범위 안의 가장 가까운 snapshot 부터, 범위 안의 snapshot 이 없다면 처음부터 replay 합니다.
+func (b *baseManager[S, R]) getStateUntil(pk eventsourcing.PartitionKey, t *eventsourcing.Tracker, within func(eventNo int, eventAt time.Time) bool) (*eventsourcing.State[S, R], error) {
+	state, err := b.getSnapshotWithin(pk, within)
+	if err != nil {
+		return nil, err
+	}
+	t.SetSnapshotHit(state != nil)
+	var eventNo int
+	if state != nil {
+		eventNo = (*state.State()).GetLastEvent().EventNo
+	}
+
+	events, err := b.events(pk, eventNo) // 서명은 replay 에서 검증
+	if err != nil {
+		return nil, err
+	}
+	// 이벤트는 eventNo 순서이므로 처음으로 범위를 벗어난 이벤트 전까지만 replay 한다
+	bound := len(events)
+	for i, e := range events {
+		if !within(e.EventNo, e.EventAt) {
+			bound = i
+			break
+		}
+	}
+	return b.replay(pk, t, state, events[:bound])
+}
+
+// getSnapshotWithin | within 을 만족하는 snapshot 중 가장 최근 snapshot 을 가져옵니다.
+// snapshot history 를 보관하는 storage 라면 history 에서 찾고, 아니면 현재 snapshot 이 범위 안에 있을 때만 사용합니다.
+func (b *baseManager[S, R]) getSnapshotWithin(pk eventsourcing.PartitionKey, within func(eventNo int, eventAt time.Time) bool) (*eventsourcing.State[S, R], error) {
+	if history, ok := b.ss.(eventsourcing.StateSnapshotHistoryStorage[S, R]); ok {
+		infos, err := history.GetSnapshotHistory(pk)
+		if err != nil {
+			return nil, eventsourcing.NewSnapshotStorageError(err)
+		}
+		for i := len(infos) - 1; i >= 0; i-- {
+			if within(infos[i].EventNo, infos[i].EventAt) {
+				state, err := history.GetSnapshotAtOrBefore(pk, infos[i].EventNo)
+				if err != nil {
+					return nil, eventsourcing.NewSnapshotStorageError(err)
+				}
+				return state, nil
+			}
+		}
+		return nil, nil
+	}
+
+	state, err := b.GetStateSnapshot(pk)
+	if err != nil || state == nil {
+		return nil, err
+	}
+	if last := (*state.State()).GetLastEvent(); last == nil || !within(last.EventNo, last.EventAt) {
+		return nil, nil // snapshot 이 범위를 넘어섰으므로 사용할 수 없음
+	}
+	return state, nil
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	es "eventsourcing"
+	"sync"
+)
+
+var (
+	_ es.RebuildCheckpointStorage = &RebuildCheckpointStorage{}
+)
+
+// RebuildCheckpointStorage | 메모리에 rebuild 작업 별로 끝난 pk 를 저장하는 RebuildCheckpointStorage 구현체
+type RebuildCheckpointStorage struct {
+	jobStorage map[string]map[es.PartitionKey]bool
+	locker     sync.RWMutex
+}
+
+func NewRebuildCheckpointStorage() *RebuildCheckpointStorage {
+	return &RebuildCheckpointStorage{
+		jobStorage: make(map[string]map[es.PartitionKey]bool),
+	}
+}
+
+func (a *RebuildCheckpointStorage) MarkRebuilt(jobId string, pk es.PartitionKey) error {
+	a.locker.Lock()
+	defer a.locker.Unlock()
+
+	if _, ok := a.jobStorage[jobId]; !ok {
+		a.jobStorage[jobId] = make(map[es.PartitionKey]bool)
+	}
+	a.jobStorage[jobId][pk] = true
+	return nil
+}
+
+func (a *RebuildCheckpointStorage) IsRebuilt(jobId string, pk es.PartitionKey) (bool, error) {
+	a.locker.RLock()
+	defer a.locker.RUnlock()
+
+	return a.jobStorage[jobId][pk], nil
+}
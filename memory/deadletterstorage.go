@@ -0,0 +1,116 @@
+package memory
+
+import (
+	es "eventsourcing"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	_ es.DeadLetterStorage[any] = &DeadLetterStorage[any]{}
+)
+
+// DeadLetterStorage | 메모리에 pk 별로 격리된 event 를 저장하는 DeadLetterStorage 구현체
+type DeadLetterStorage[R any] struct {
+	pkLetterStorage map[es.PartitionKey]map[int]es.DeadLetter[R] // key : pk, eventNo
+	locker          sync.RWMutex
+}
+
+func NewDeadLetterStorage[R any]() *DeadLetterStorage[R] {
+	return &DeadLetterStorage[R]{
+		pkLetterStorage: make(map[es.PartitionKey]map[int]es.DeadLetter[R]),
+	}
+}
+
+func (a *DeadLetterStorage[R]) AddDeadLetter(letter *es.DeadLetter[R]) error {
+	a.locker.Lock()
+	defer a.locker.Unlock()
+
+	if _, ok := a.pkLetterStorage[letter.PartitionKey]; !ok {
+		a.pkLetterStorage[letter.PartitionKey] = make(map[int]es.DeadLetter[R])
+	}
+	a.pkLetterStorage[letter.PartitionKey][letter.EventNo] = *letter
+	return nil
+}
+
+func (a *DeadLetterStorage[R]) GetDeadLetters(pk es.PartitionKey) ([]*es.DeadLetter[R], error) {
+	a.locker.RLock()
+	defer a.locker.RUnlock()
+
+	letters := make([]*es.DeadLetter[R], 0, len(a.pkLetterStorage[pk]))
+	for _, letter := range a.pkLetterStorage[pk] {
+		letter := letter
+		letters = append(letters, &letter)
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i].EventNo < letters[j].EventNo })
+	return letters, nil
+}
+
+// ListDeadLetters | cursor 는 마지막으로 조회한 격리 event 의 "eventNo:pk"
+func (a *DeadLetterStorage[R]) ListDeadLetters(cursor string, limit int) ([]*es.DeadLetter[R], string, error) {
+	a.locker.RLock()
+	defer a.locker.RUnlock()
+
+	var cursorPk es.PartitionKey
+	cursorNo := 0
+	if cursor != "" {
+		no, pk, ok := strings.Cut(cursor, ":")
+		n, err := strconv.Atoi(no)
+		if !ok || err != nil {
+			return nil, "", fmt.Errorf("invalid cursor. cursor(%s)", cursor)
+		}
+		cursorPk, cursorNo = es.PartitionKey(pk), n
+	}
+
+	var letters []*es.DeadLetter[R]
+	for pk, pkLetters := range a.pkLetterStorage {
+		for no, letter := range pkLetters {
+			if cursor != "" && (pk < cursorPk || pk == cursorPk && no <= cursorNo) {
+				continue
+			}
+			letter := letter
+			letters = append(letters, &letter)
+		}
+	}
+	sort.Slice(letters, func(i, j int) bool {
+		if letters[i].PartitionKey != letters[j].PartitionKey {
+			return letters[i].PartitionKey < letters[j].PartitionKey
+		}
+		return letters[i].EventNo < letters[j].EventNo
+	})
+
+	next := ""
+	if limit > 0 && len(letters) > limit {
+		letters = letters[:limit]
+		last := letters[limit-1]
+		next = fmt.Sprintf("%d:%s", last.EventNo, last.PartitionKey)
+	}
+	return letters, next, nil
+}
+
+func (a *DeadLetterStorage[R]) CorrectDeadLetter(pk es.PartitionKey, eventNo int, correctedBy es.EventId) error {
+	a.locker.Lock()
+	defer a.locker.Unlock()
+
+	letter, ok := a.pkLetterStorage[pk][eventNo]
+	if !ok {
+		return fmt.Errorf("no has dead letter. pk(%s), eventNo(%d)", pk, eventNo)
+	}
+	letter.CorrectedBy = correctedBy
+	a.pkLetterStorage[pk][eventNo] = letter
+	return nil
+}
+
+func (a *DeadLetterStorage[R]) RemoveDeadLetter(pk es.PartitionKey, eventNo int) error {
+	a.locker.Lock()
+	defer a.locker.Unlock()
+
+	delete(a.pkLetterStorage[pk], eventNo)
+	if len(a.pkLetterStorage[pk]) == 0 {
+		delete(a.pkLetterStorage, pk)
+	}
+	return nil
+}
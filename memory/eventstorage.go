@@ -0,0 +1,339 @@
+// In-Memory Storages
+//
+// EventStorage, StateSnapshotStorage 를 메모리 위에 구현한다.
+// 테스트나 예제처럼 영속성이 필요하지 않은 곳에서 사용하며, 도메인 별로 따로 구현하지 않도록 제네릭으로 만든다.
+//
+
+package memory
+
+import (
+	"bytes"
+	"encoding/json"
+	es "eventsourcing"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	_ es.EventStorage[any]        = &EventStorage[any]{}
+	_ es.EventIdRangeStorage[any] = &EventStorage[any]{}
+)
+
+type Counter struct {
+	Count int32
+}
+
+func (c *Counter) Increase(delta int32) int32 {
+	return atomic.AddInt32(&c.Count, delta)
+}
+
+// EventStorage | 메모리에 Event 를 저장하는 EventStorage 구현체
+type EventStorage[R any] struct {
+	eventNoStorage map[es.PartitionKey]*Counter              // pk 의 event 번호를 저장하는 스토리지
+	pkGroupStorage map[es.PartitionKey][]es.EventId          // pk 의 event id 리스트를 저장하는 스토리지
+	eventStorage   map[es.EventId]es.Event[R]                // event id 별로 event 를 저장하는 스토리지
+	sortedIds      []es.EventId                              // 전체 event id 를 정렬해서 들고 있는 인덱스, id 범위 조회에 사용
+	idempotencyKey map[es.PartitionKey]map[string]es.EventId // pk 별 멱등키의 event id 를 저장하는 스토리지
+	pkLockers      map[es.PartitionKey]*sync.RWMutex         // pk 안에서 dirty read 를 방지하기 위한 RWMutex
+	esLocker       sync.RWMutex                              // storage 의 map 들을 보호하는 RWMutex
+}
+
+func NewEventStorage[R any]() *EventStorage[R] {
+	return &EventStorage[R]{
+		eventNoStorage: make(map[es.PartitionKey]*Counter),
+		pkGroupStorage: make(map[es.PartitionKey][]es.EventId),
+		eventStorage:   make(map[es.EventId]es.Event[R]),
+		idempotencyKey: make(map[es.PartitionKey]map[string]es.EventId),
+		pkLockers:      make(map[es.PartitionKey]*sync.RWMutex),
+	}
+}
+
+func (a *EventStorage[R]) getPkLocker(pk es.PartitionKey) *sync.RWMutex {
+	a.esLocker.RLock()
+	locker, ok := a.pkLockers[pk]
+	a.esLocker.RUnlock()
+	if ok {
+		return locker
+	}
+
+	// pk locker 중복 할당 방지
+	a.esLocker.Lock()
+	defer a.esLocker.Unlock()
+	if _, ok = a.pkLockers[pk]; !ok {
+		a.pkLockers[pk] = &sync.RWMutex{}
+	}
+	// locker ptr 을 리턴해야 copy 이슈로 lock 이 걸리지 않는 이슈가 발생하지 않음
+	return a.pkLockers[pk]
+}
+
+func (a *EventStorage[R]) IncreaseEventNo(pk es.PartitionKey) (eventNo int, err error) {
+	// event No 는 pk 별로 atomic 하게 증가시켜야 함
+	// counter 중복 할당을 막고, AppendEvent 의 발급과 섞이지 않도록 storage lock 을 건다.
+	a.esLocker.Lock()
+	defer a.esLocker.Unlock()
+	return int(a.counter(pk).Increase(1)), nil
+}
+
+// counter | pk 의 event 번호 counter, esLocker 를 잡고 호출한다.
+func (a *EventStorage[R]) counter(pk es.PartitionKey) *Counter {
+	counter, ok := a.eventNoStorage[pk]
+	if !ok {
+		counter = &Counter{0}
+		a.eventNoStorage[pk] = counter
+	}
+	return counter
+}
+
+func (a *EventStorage[R]) AddEvent(event *es.Event[R]) error {
+	// eventStorage 와 pkGroupStorage 를 둘다 사용하므로
+	// pk 에 write lock 을 걸어 같은 pk 의 조회가 중간 상태를 보지 않도록 한다.
+	pkLocker := a.getPkLocker(event.PartitionKey)
+	pkLocker.Lock()
+	defer pkLocker.Unlock()
+
+	a.esLocker.Lock()
+	defer a.esLocker.Unlock()
+	stored := copyEvent(event)
+	if old, ok := a.eventStorage[event.EventId]; ok {
+		// 저장된 event 는 바꾸지 않는다. 같은 내용을 다시 저장(ex. 재시도)하는 것만 받아들인다
+		return sameEvent(&old, &stored)
+	}
+	a.insert(stored)
+	return nil
+}
+
+// AppendEvent | pk 에 write lock 을 걸고 번호 발급, Seal, 저장을 한 번에 한다.
+func (a *EventStorage[R]) AppendEvent(ea *es.EventAppend[R]) (*es.Event[R], bool, error) {
+	pk := ea.Event.PartitionKey
+	pkLocker := a.getPkLocker(pk)
+	pkLocker.Lock()
+	defer pkLocker.Unlock()
+
+	a.esLocker.Lock()
+	defer a.esLocker.Unlock()
+	if old, ok := a.eventStorage[ea.Event.EventId]; ok {
+		copied := copyEvent(&old)
+		return &copied, false, nil // 이미 저장된 event (ex. 재시도)
+	}
+	if old := a.idempotentEvent(pk, ea.Event.IdempotencyKey, ea.IdempotencySince); old != nil {
+		return old, false, nil // 같은 멱등키로 저장된 event
+	}
+
+	var last *es.Event[R]
+	if group := a.pkGroupStorage[pk]; len(group) > 0 {
+		e := a.eventStorage[group[len(group)-1]]
+		e = copyEvent(&e)
+		last = &e
+	}
+	counter := a.counter(pk)
+	stored := copyEvent(ea.Event)
+	stored.EventNo = int(counter.Count) + 1
+	if ea.Seal != nil {
+		if err := ea.Seal(&stored, last); err != nil {
+			return nil, false, err // 저장하지 않았으므로 번호도 발급하지 않는다
+		}
+	}
+	counter.Increase(1)
+	a.insert(stored)
+	appended := copyEvent(&stored)
+	return &appended, true, nil
+}
+
+// insert | event 를 저장하고 인덱스에 추가한다. pk 와 storage 의 write lock 을 잡고 호출한다.
+func (a *EventStorage[R]) insert(stored es.Event[R]) {
+	a.eventStorage[stored.EventId] = stored
+	a.pkGroupStorage[stored.PartitionKey] = append(a.pkGroupStorage[stored.PartitionKey], stored.EventId)
+	if stored.IdempotencyKey != "" {
+		if _, ok := a.idempotencyKey[stored.PartitionKey]; !ok {
+			a.idempotencyKey[stored.PartitionKey] = make(map[string]es.EventId)
+		}
+		a.idempotencyKey[stored.PartitionKey][stored.IdempotencyKey] = stored.EventId
+	}
+
+	// 정렬된 id 로 발급된다면 뒤에 붙이기만 하면 되고, 아닌 경우만 자리를 찾아 넣는다
+	i := sort.Search(len(a.sortedIds), func(i int) bool { return a.sortedIds[i] > stored.EventId })
+	a.sortedIds = append(a.sortedIds, "")
+	copy(a.sortedIds[i+1:], a.sortedIds[i:])
+	a.sortedIds[i] = stored.EventId
+}
+
+// copyEvent | 저장 이후 호출자가 map 을 바꿔도 저장된 event 가 바뀌지 않도록 Metadata 까지 복사, 저장할 때와 돌려줄 때 모두 복사한다.
+func copyEvent[R any](event *es.Event[R]) es.Event[R] {
+	stored := *event
+	if event.Metadata != nil {
+		stored.Metadata = make(map[string]string, len(event.Metadata))
+		for k, v := range event.Metadata {
+			stored.Metadata[k] = v
+		}
+	}
+	return stored
+}
+
+// sameEvent | 두 event 의 json 이 같으면 nil, 다르면 ErrEventIdConflict
+func sameEvent[R any](a, b *es.Event[R]) error {
+	x, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	y, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(x, y) {
+		return es.ErrEventIdConflict
+	}
+	return nil
+}
+
+func (a *EventStorage[R]) GetEvent(id es.EventId) (*es.Event[R], error) {
+	a.esLocker.RLock()
+	defer a.esLocker.RUnlock()
+
+	event, ok := a.eventStorage[id]
+	if !ok {
+		return nil, nil
+	}
+	event = copyEvent(&event)
+	return &event, nil
+}
+
+func (a *EventStorage[R]) GetEvents(pk es.PartitionKey) ([]*es.Event[R], error) {
+	return a.GetEventsAfterEventNo(pk, 0)
+}
+
+func (a *EventStorage[R]) GetEventsAfterEventNo(pk es.PartitionKey, eventNo int) ([]*es.Event[R], error) {
+	// 이벤트 리스트를 조회할 때는 dirty read 를 방지하기 위해
+	// Read Lock 을 건다
+	locker := a.getPkLocker(pk)
+	locker.RLock()
+	defer locker.RUnlock()
+
+	a.esLocker.RLock()
+	defer a.esLocker.RUnlock()
+
+	eventIds := a.pkGroupStorage[pk]
+	ptrEvents := make([]*es.Event[R], 0, len(eventIds))
+	for _, eventId := range eventIds {
+		if e := a.eventStorage[eventId]; e.EventNo > eventNo {
+			e = copyEvent(&e)
+			ptrEvents = append(ptrEvents, &e)
+		}
+	}
+	return ptrEvents, nil
+}
+
+func (a *EventStorage[R]) GetLastEvent(pk es.PartitionKey) (*es.Event[R], error) {
+	// 쌓인 마지막 이벤트를 가져올 때, dirty read 를 방지하기 위해
+	// Read lock 을 건다
+	locker := a.getPkLocker(pk)
+	locker.RLock()
+	defer locker.RUnlock()
+
+	a.esLocker.RLock()
+	defer a.esLocker.RUnlock()
+
+	group := a.pkGroupStorage[pk]
+	if len(group) == 0 {
+		return nil, nil
+	}
+	event := a.eventStorage[group[len(group)-1]]
+	event = copyEvent(&event)
+	return &event, nil
+}
+
+func (a *EventStorage[R]) GetEventByIdempotencyKey(pk es.PartitionKey, key string, since time.Time) (*es.Event[R], error) {
+	a.esLocker.RLock()
+	defer a.esLocker.RUnlock()
+	return a.idempotentEvent(pk, key, since), nil
+}
+
+// idempotentEvent | since 이후 같은 멱등키로 저장된 event, 없으면 nil. storage lock 을 잡고 호출한다.
+func (a *EventStorage[R]) idempotentEvent(pk es.PartitionKey, key string, since time.Time) *es.Event[R] {
+	if key == "" {
+		return nil
+	}
+	eventId, ok := a.idempotencyKey[pk][key]
+	if !ok {
+		return nil
+	}
+	event := a.eventStorage[eventId]
+	if event.EventAt.Before(since) {
+		return nil // 기억하는 기간이 지난 키
+	}
+	event = copyEvent(&event)
+	return &event
+}
+
+// ExpireIdempotencyKeys | before 이전에 저장된 멱등키를 지우고 지운 개수를 돌려준다.
+// 조회와 저장은 기간이 지난 키를 무시만 하므로, 메모리를 줄이려면 주기적으로 호출한다.
+func (a *EventStorage[R]) ExpireIdempotencyKeys(before time.Time) int {
+	a.esLocker.Lock()
+	defer a.esLocker.Unlock()
+
+	expired := 0
+	for pk, keys := range a.idempotencyKey {
+		for key, eventId := range keys {
+			if a.eventStorage[eventId].EventAt.Before(before) {
+				delete(keys, key)
+				expired++
+			}
+		}
+		if len(keys) == 0 {
+			delete(a.idempotencyKey, pk)
+		}
+	}
+	return expired
+}
+
+// GetEventsBetweenIds | from <= EventId < to 인 event 들을 partition 에 상관없이 EventId 순서로 조회한다. to 가 비어있으면 끝까지 조회한다.
+func (a *EventStorage[R]) GetEventsBetweenIds(from, to es.EventId) ([]*es.Event[R], error) {
+	a.esLocker.RLock()
+	defer a.esLocker.RUnlock()
+
+	start := sort.Search(len(a.sortedIds), func(i int) bool { return a.sortedIds[i] >= from })
+	ptrEvents := make([]*es.Event[R], 0)
+	for _, eventId := range a.sortedIds[start:] {
+		if to != "" && eventId >= to {
+			break
+		}
+		e := a.eventStorage[eventId]
+		e = copyEvent(&e)
+		ptrEvents = append(ptrEvents, &e)
+	}
+	return ptrEvents, nil
+}
+
+// ListPartitions | pk 를 정렬하여 cursor 다음부터 limit 개의 통계를 조회한다. cursor 는 이전에 조회한 마지막 pk 이다.
+func (a *EventStorage[R]) ListPartitions(cursor string, limit int) ([]*es.PartitionStat, string, error) {
+	a.esLocker.RLock()
+	defer a.esLocker.RUnlock()
+
+	pks := make([]es.PartitionKey, 0, len(a.pkGroupStorage))
+	for pk := range a.pkGroupStorage {
+		if string(pk) > cursor {
+			pks = append(pks, pk)
+		}
+	}
+	sort.Slice(pks, func(i, j int) bool { return pks[i] < pks[j] })
+
+	next := ""
+	if limit > 0 && len(pks) > limit {
+		pks = pks[:limit]
+		next = string(pks[limit-1])
+	}
+	stats := make([]*es.PartitionStat, len(pks))
+	for i, pk := range pks {
+		eventIds := a.pkGroupStorage[pk]
+		last := a.eventStorage[eventIds[len(eventIds)-1]]
+		stats[i] = &es.PartitionStat{
+			PartitionKey:  pk,
+			EventCount:    len(eventIds),
+			LastEventNo:   last.EventNo,
+			LastEventAt:   last.EventAt,
+			LastEventType: last.EventType,
+		}
+	}
+	return stats, next, nil
+}
@@ -0,0 +1,209 @@
+package memory
+
+import (
+	"errors"
+	es "eventsourcing"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var testEventType = &es.EventType{Domain: "test", Name: "test", Version: "v1"}
+
+func TestEventStorage_GetEventsBetweenIds(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := es.NewFakeClock(start)
+	gen := es.NewMonotonicIdGenerator(clock)
+	storage := NewEventStorage[string]()
+
+	// 두 partition 에 번갈아 1초 간격으로 event 를 쌓는다
+	for i := 0; i < 6; i++ {
+		pk := es.PartitionKey("a")
+		if i%2 == 1 {
+			pk = "b"
+		}
+		no, _ := storage.IncreaseEventNo(pk)
+		if err := storage.AddEvent(es.NewEventWith[string](gen, clock, pk, testEventType, no, nil)); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(time.Second)
+	}
+
+	events, err := storage.GetEventsBetweenIds(
+		es.MonotonicIdLowerBound(start.Add(1*time.Second)),
+		es.MonotonicIdLowerBound(start.Add(4*time.Second)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("len(events) = %d, want 3", len(events))
+	}
+	for i, e := range events {
+		if want := start.Add(time.Duration(i+1) * time.Second); !e.EventAt.Equal(want) {
+			t.Errorf("events[%d].EventAt = %s, want %s", i, e.EventAt, want)
+		}
+	}
+
+	all, _ := storage.GetEventsBetweenIds("", "")
+	if len(all) != 6 {
+		t.Errorf("len(all) = %d, want 6", len(all))
+	}
+}
+
+func TestEventStorage_ListPartitions(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := es.NewFakeClock(start)
+	storage := NewEventStorage[string]()
+
+	// pk_0 은 1개, pk_1 은 2개 ... pk_4 는 5개의 event
+	for i := 0; i < 5; i++ {
+		pk := es.PartitionKey(fmt.Sprintf("pk_%d", i))
+		for j := 0; j <= i; j++ {
+			no, _ := storage.IncreaseEventNo(pk)
+			if err := storage.AddEvent(es.NewEventWith[string](es.DefaultIdGenerator, clock, pk, testEventType, no, nil)); err != nil {
+				t.Fatal(err)
+			}
+			clock.Advance(time.Second)
+		}
+	}
+
+	var stats []*es.PartitionStat
+	cursor := ""
+	for pages := 0; ; pages++ {
+		page, next, err := storage.ListPartitions(cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pages > 3 {
+			t.Fatalf("too many pages. cursor(%s)", next)
+		}
+		stats = append(stats, page...)
+		if cursor = next; cursor == "" {
+			break
+		}
+	}
+
+	if len(stats) != 5 {
+		t.Fatalf("len(stats) = %d, want 5", len(stats))
+	}
+	for i, stat := range stats {
+		if want := es.PartitionKey(fmt.Sprintf("pk_%d", i)); stat.PartitionKey != want {
+			t.Errorf("stats[%d].PartitionKey = %s, want %s", i, stat.PartitionKey, want)
+		}
+		if stat.EventCount != i+1 || stat.LastEventNo != i+1 || stat.LastEventType.String() != testEventType.String() {
+			t.Errorf("stats[%d] = %s", i, es.JsonString(stat))
+		}
+	}
+	if want := clock.Now().Add(-time.Second); !stats[4].LastEventAt.Equal(want) {
+		t.Errorf("stats[4].LastEventAt = %s, want %s", stats[4].LastEventAt, want)
+	}
+}
+
+func TestEventStorage_AddEventRetry(t *testing.T) {
+	storage := NewEventStorage[string]()
+	request := "a"
+	e := es.NewEvent[string]("pk", testEventType, 1, &request)
+	if err := storage.AddEvent(e); err != nil {
+		t.Fatal(err)
+	}
+
+	// 같은 내용은 다시 저장해도 event 가 늘어나지 않는다
+	retried := *e
+	if err := storage.AddEvent(&retried); err != nil {
+		t.Fatal(err)
+	}
+	if events, _ := storage.GetEvents("pk"); len(events) != 1 {
+		t.Errorf("len(events) = %d, want 1", len(events))
+	}
+
+	// 다른 내용으로 덮어쓸 수 없다
+	forged := "forged"
+	edited := *e
+	edited.Request = &forged
+	if err := storage.AddEvent(&edited); !errors.Is(err, es.ErrEventIdConflict) {
+		t.Errorf("edited err = %v", err)
+	}
+	if stored, _ := storage.GetEvent(e.EventId); *stored.Request != "a" {
+		t.Errorf("stored.Request = %s", *stored.Request)
+	}
+}
+
+func TestEventStorage_ExpireIdempotencyKeys(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := es.NewFakeClock(start)
+	storage := NewEventStorage[string]()
+	for _, key := range []string{"old", "new"} {
+		e := es.NewEventWith[string](es.DefaultIdGenerator, clock, "pk", testEventType, 0, nil)
+		e.IdempotencyKey = key
+		if _, appended, err := storage.AppendEvent(&es.EventAppend[string]{Event: e}); err != nil || !appended {
+			t.Fatalf("appended = %v, %v", appended, err)
+		}
+		clock.Advance(time.Hour)
+	}
+
+	// 기간이 지난 키는 조회되지 않지만 조회가 지우지는 않는다
+	since := start.Add(30 * time.Minute)
+	if e, _ := storage.GetEventByIdempotencyKey("pk", "old", since); e != nil {
+		t.Errorf("expired key = %s", es.JsonString(e))
+	}
+	if e, _ := storage.GetEventByIdempotencyKey("pk", "old", start); e == nil {
+		t.Error("read path must not expire keys")
+	}
+
+	if expired := storage.ExpireIdempotencyKeys(since); expired != 1 {
+		t.Errorf("expired = %d, want 1", expired)
+	}
+	if e, _ := storage.GetEventByIdempotencyKey("pk", "old", start); e != nil {
+		t.Error("expired key must be removed")
+	}
+	if e, _ := storage.GetEventByIdempotencyKey("pk", "new", since); e == nil || e.EventNo != 2 {
+		t.Errorf("new key = %s", es.JsonString(e))
+	}
+}
+
+func TestEventStorage_ReadCopiesMetadata(t *testing.T) {
+	storage := NewEventStorage[string]()
+	e := es.NewEvent[string]("pk", testEventType, 0, nil)
+	e.Metadata = map[string]string{"k": "v"}
+	e.IdempotencyKey = "key"
+	appended, _, err := storage.AppendEvent(&es.EventAppend[string]{Event: e})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 조회한 event 의 Metadata 를 바꿔도 저장된 event 는 그대로
+	reads := map[string]func() *es.Event[string]{
+		"AppendEvent": func() *es.Event[string] { return appended },
+		"GetEvent": func() *es.Event[string] {
+			read, _ := storage.GetEvent(e.EventId)
+			return read
+		},
+		"GetEvents": func() *es.Event[string] {
+			read, _ := storage.GetEvents("pk")
+			return read[0]
+		},
+		"GetEventsBetweenIds": func() *es.Event[string] {
+			read, _ := storage.GetEventsBetweenIds("", "")
+			return read[0]
+		},
+		"GetLastEvent": func() *es.Event[string] {
+			read, _ := storage.GetLastEvent("pk")
+			return read
+		},
+		"GetEventByIdempotencyKey": func() *es.Event[string] {
+			read, _ := storage.GetEventByIdempotencyKey("pk", "key", time.Time{})
+			return read
+		},
+		"AppendEvent retry": func() *es.Event[string] {
+			read, _, _ := storage.AppendEvent(&es.EventAppend[string]{Event: e})
+			return read
+		},
+	}
+	for name, read := range reads {
+		read().Metadata["k"] = name
+		if stored, _ := storage.GetEvent(e.EventId); stored.Metadata["k"] != "v" {
+			t.Errorf("%s: stored metadata = %v", name, stored.Metadata)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	es "eventsourcing"
+	"sort"
+	"sync"
+)
+
+var (
+	_ es.HashCheckpointStorage = &HashCheckpointStorage{}
+)
+
+// HashCheckpointStorage | 메모리에 pk 별로 HashCheckpoint 를 eventNo 순서로 보관하는 HashCheckpointStorage 구현체
+type HashCheckpointStorage struct {
+	pkCheckpointStorage map[es.PartitionKey][]es.HashCheckpoint // pk 별 checkpoint 목록, eventNo 오름차순
+	locker              sync.RWMutex
+}
+
+func NewHashCheckpointStorage() *HashCheckpointStorage {
+	return &HashCheckpointStorage{
+		pkCheckpointStorage: make(map[es.PartitionKey][]es.HashCheckpoint),
+	}
+}
+
+func (a *HashCheckpointStorage) SaveCheckpoint(checkpoint *es.HashCheckpoint) error {
+	a.locker.Lock()
+	defer a.locker.Unlock()
+
+	checkpoints := a.pkCheckpointStorage[checkpoint.PartitionKey]
+	i := sort.Search(len(checkpoints), func(i int) bool { return checkpoints[i].EventNo >= checkpoint.EventNo })
+	if i < len(checkpoints) && checkpoints[i].EventNo == checkpoint.EventNo {
+		checkpoints[i] = *checkpoint // 같은 eventNo 는 덮어씀
+	} else {
+		checkpoints = append(checkpoints, es.HashCheckpoint{})
+		copy(checkpoints[i+1:], checkpoints[i:])
+		checkpoints[i] = *checkpoint
+	}
+	a.pkCheckpointStorage[checkpoint.PartitionKey] = checkpoints
+	return nil
+}
+
+func (a *HashCheckpointStorage) GetCheckpoints(pk es.PartitionKey) ([]*es.HashCheckpoint, error) {
+	a.locker.RLock()
+	defer a.locker.RUnlock()
+
+	checkpoints := a.pkCheckpointStorage[pk]
+	result := make([]*es.HashCheckpoint, len(checkpoints))
+	for i := range checkpoints {
+		checkpoint := checkpoints[i]
+		result[i] = &checkpoint
+	}
+	return result, nil
+}
+
+func (a *HashCheckpointStorage) GetLastCheckpoint(pk es.PartitionKey) (*es.HashCheckpoint, error) {
+	a.locker.RLock()
+	defer a.locker.RUnlock()
+
+	checkpoints := a.pkCheckpointStorage[pk]
+	if len(checkpoints) == 0 {
+		return nil, nil
+	}
+	checkpoint := checkpoints[len(checkpoints)-1]
+	return &checkpoint, nil
+}
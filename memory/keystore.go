@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"crypto/rand"
+	es "eventsourcing"
+	"strconv"
+	"sync"
+)
+
+var (
+	_ es.KeyStore = &KeyStore{}
+)
+
+// KeyStore | 메모리에 pk 별 data key 를 보관하는 KeyStore 구현체
+type KeyStore struct {
+	current map[es.PartitionKey]string            // pk 의 암호화에 사용하는 keyId
+	keys    map[es.PartitionKey]map[string][]byte // key : pk, keyId
+	seq     int
+	locker  sync.RWMutex
+}
+
+func NewKeyStore() *KeyStore {
+	return &KeyStore{
+		current: make(map[es.PartitionKey]string),
+		keys:    make(map[es.PartitionKey]map[string][]byte),
+	}
+}
+
+func (a *KeyStore) DataKey(pk es.PartitionKey) (string, []byte, error) {
+	a.locker.Lock()
+	defer a.locker.Unlock()
+
+	if keyId, ok := a.current[pk]; ok {
+		return keyId, a.keys[pk][keyId], nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", nil, err
+	}
+	a.seq++
+	keyId := strconv.Itoa(a.seq) // 지운 뒤 다시 만든 key 는 다른 id 를 갖는다
+	if a.keys[pk] == nil {
+		a.keys[pk] = make(map[string][]byte)
+	}
+	a.keys[pk][keyId] = key
+	a.current[pk] = keyId
+	return keyId, key, nil
+}
+
+func (a *KeyStore) GetKey(pk es.PartitionKey, keyId string) ([]byte, error) {
+	a.locker.RLock()
+	defer a.locker.RUnlock()
+
+	key, ok := a.keys[pk][keyId]
+	if !ok {
+		return nil, es.ErrKeyShredded
+	}
+	return key, nil
+}
+
+func (a *KeyStore) DeleteKeys(pk es.PartitionKey) error {
+	a.locker.Lock()
+	defer a.locker.Unlock()
+
+	delete(a.current, pk)
+	delete(a.keys, pk)
+	return nil
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	es "eventsourcing"
+	"sort"
+	"sync"
+)
+
+// SnapshotHistoryStorage | 메모리에 pk 별로 여러 State Snapshot 을 eventNo 순서로 보관하는 StateSnapshotHistoryStorage 구현체
+type SnapshotHistoryStorage[S es.CommonState[R], R any] struct {
+	retention         es.RetentionPolicy
+	pkSnapshotStorage map[es.PartitionKey][]es.State[S, R] // pk 별 snapshot 목록, eventNo 오름차순
+	ssLocker          sync.RWMutex
+}
+
+// NewSnapshotHistoryStorage | retention 정책으로 snapshot 을 보관한다. retention 이 nil 이면 모두 보관한다.
+func NewSnapshotHistoryStorage[S es.CommonState[R], R any](retention es.RetentionPolicy) *SnapshotHistoryStorage[S, R] {
+	if retention == nil {
+		retention = es.KeepAll()
+	}
+	return &SnapshotHistoryStorage[S, R]{
+		retention:         retention,
+		pkSnapshotStorage: make(map[es.PartitionKey][]es.State[S, R]),
+	}
+}
+
+func (a *SnapshotHistoryStorage[S, R]) SaveSnapshot(pk es.PartitionKey, state *es.State[S, R]) error {
+	a.ssLocker.Lock()
+	defer a.ssLocker.Unlock()
+
+	// 호출자가 이후에 state 를 바꾸더라도 history 가 바뀌지 않도록 복사해서 저장
+	saved, err := state.Clone()
+	if err != nil {
+		return err
+	}
+	eventNo := snapshotEventNo(saved)
+
+	snapshots := a.pkSnapshotStorage[pk]
+	i := sort.Search(len(snapshots), func(i int) bool { return snapshotEventNo(&snapshots[i]) >= eventNo })
+	if i < len(snapshots) && snapshotEventNo(&snapshots[i]) == eventNo {
+		snapshots[i] = *saved // 같은 eventNo 는 덮어씀
+	} else {
+		snapshots = append(snapshots, es.State[S, R]{})
+		copy(snapshots[i+1:], snapshots[i:])
+		snapshots[i] = *saved
+	}
+
+	// 보관 정책에 따라 정리
+	keep := make(map[int]bool)
+	for _, info := range a.retention.Retain(snapshotInfos(snapshots)) {
+		keep[info.EventNo] = true
+	}
+	retained := make([]es.State[S, R], 0, len(keep))
+	for _, snapshot := range snapshots {
+		if keep[snapshotEventNo(&snapshot)] {
+			retained = append(retained, snapshot)
+		}
+	}
+	a.pkSnapshotStorage[pk] = retained
+	return nil
+}
+
+func (a *SnapshotHistoryStorage[S, R]) GetSnapshot(pk es.PartitionKey) (state *es.State[S, R], err error) {
+	a.ssLocker.RLock()
+	defer a.ssLocker.RUnlock()
+
+	snapshots := a.pkSnapshotStorage[pk]
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+	return snapshots[len(snapshots)-1].Clone()
+}
+
+func (a *SnapshotHistoryStorage[S, R]) GetSnapshotAtOrBefore(pk es.PartitionKey, eventNo int) (state *es.State[S, R], err error) {
+	a.ssLocker.RLock()
+	defer a.ssLocker.RUnlock()
+
+	snapshots := a.pkSnapshotStorage[pk]
+	i := sort.Search(len(snapshots), func(i int) bool { return snapshotEventNo(&snapshots[i]) > eventNo })
+	if i == 0 {
+		return nil, nil
+	}
+	return snapshots[i-1].Clone()
+}
+
+func (a *SnapshotHistoryStorage[S, R]) GetSnapshotHistory(pk es.PartitionKey) ([]es.SnapshotInfo, error) {
+	a.ssLocker.RLock()
+	defer a.ssLocker.RUnlock()
+
+	return snapshotInfos(a.pkSnapshotStorage[pk]), nil
+}
+
+func snapshotInfos[S es.CommonState[R], R any](snapshots []es.State[S, R]) []es.SnapshotInfo {
+	infos := make([]es.SnapshotInfo, len(snapshots))
+	for i := range snapshots {
+		if last := (*snapshots[i].State()).GetLastEvent(); last != nil {
+			infos[i] = es.SnapshotInfo{EventNo: last.EventNo, EventAt: last.EventAt}
+		}
+	}
+	return infos
+}
+
+func snapshotEventNo[S es.CommonState[R], R any](state *es.State[S, R]) int {
+	if last := (*state.State()).GetLastEvent(); last != nil {
+		return last.EventNo
+	}
+	return 0
+}
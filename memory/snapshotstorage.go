@@ -0,0 +1,43 @@
+package memory
+
+import (
+	es "eventsourcing"
+	"sync"
+)
+
+// SnapshotStorage | 메모리에 pk 별 State Snapshot 을 하나씩 저장하는 StateSnapshotStorage 구현체
+// 저장할 때와 조회할 때 모두 State.Clone 으로 복사하므로, 호출자가 state 를 바꿔도 저장된 snapshot 은 바뀌지 않는다.
+type SnapshotStorage[S es.CommonState[R], R any] struct {
+	pkSnapshotStorage map[es.PartitionKey]es.State[S, R]
+	ssLocker          sync.RWMutex
+}
+
+func NewSnapshotStorage[S es.CommonState[R], R any]() *SnapshotStorage[S, R] {
+	return &SnapshotStorage[S, R]{
+		pkSnapshotStorage: make(map[es.PartitionKey]es.State[S, R]),
+	}
+}
+
+func (a *SnapshotStorage[S, R]) SaveSnapshot(pk es.PartitionKey, state *es.State[S, R]) error {
+	a.ssLocker.Lock()
+	defer a.ssLocker.Unlock()
+
+	// 호출자가 이후에 state 를 바꾸더라도 snapshot 이 바뀌지 않도록 복사해서 저장
+	saved, err := state.Clone()
+	if err != nil {
+		return err
+	}
+	a.pkSnapshotStorage[pk] = *saved
+	return nil
+}
+
+func (a *SnapshotStorage[S, R]) GetSnapshot(pk es.PartitionKey) (state *es.State[S, R], err error) {
+	a.ssLocker.RLock()
+	defer a.ssLocker.RUnlock()
+
+	snapshot, ok := a.pkSnapshotStorage[pk]
+	if !ok {
+		return nil, nil
+	}
+	return snapshot.Clone() // 저장된 state 를 밖에 노출하지 않음
+}
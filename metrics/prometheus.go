@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"eventsourcing"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Prometheus Exporter
+//
+// Manager, Processor 의 계측 정보를 모아서 Prometheus text format(0.0.4) 으로 내보내는 Instrumentation 구현체
+// - eventsourcing_operations_total{operation, domain, event_name, event_version, outcome} : 작업 수, 여러 event type 을 다루는 replay, apply 작업은 event_name, event_version 이 빈 값
+//   EventType.String() 은 '_' 로 이어붙여 다른 event type 과 겹칠 수 있으므로 EventTypeKey 의 값을 label 로 나눈다.
+// - eventsourcing_operation_duration_seconds{operation, domain} : 작업 시간 histogram
+// - eventsourcing_events_replayed_total{operation, domain} : 적용에 성공한 event 수
+// - eventsourcing_snapshot_lookups_total{operation, domain, result} : snapshot 조회 결과(hit, miss) 수
+//
+//	exporter := metrics.NewPrometheusExporter(nil)
+//	m := manager.NewBaseManager(..., manager.WithInstrumentation[S, R](exporter))
+//	http.Handle("/metrics", exporter)
+
+// DefaultBuckets | 작업 시간 histogram 의 기본 bucket (초)
+var DefaultBuckets = []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+var _ eventsourcing.Instrumentation = &PrometheusExporter{}
+
+// PrometheusExporter | Prometheus text format 으로 계측 정보를 내보내는 Instrumentation, http.Handler 로 사용할 수 있다.
+type PrometheusExporter struct {
+	buckets    []float64
+	operations map[string]float64    // key : 정렬된 label
+	durations  map[string]*histogram // key : 정렬된 label
+	replayed   map[string]float64
+	snapshots  map[string]float64
+	locker     sync.Mutex
+}
+
+type histogram struct {
+	counts []uint64 // bucket 별 누적이 아닌 개수
+	count  uint64
+	sum    float64
+}
+
+// NewPrometheusExporter | buckets 가 비어있으면 DefaultBuckets 를 사용한다.
+func NewPrometheusExporter(buckets []float64) *PrometheusExporter {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &PrometheusExporter{
+		buckets:    sorted,
+		operations: make(map[string]float64),
+		durations:  make(map[string]*histogram),
+		replayed:   make(map[string]float64),
+		snapshots:  make(map[string]float64),
+	}
+}
+
+// StartSpan | exporter 는 tracing 을 하지 않는다. tracing 은 eventsourcing.Instrumentations 로 함께 등록한다.
+func (p *PrometheusExporter) StartSpan(eventsourcing.Operation, eventsourcing.PartitionKey, *eventsourcing.EventType) eventsourcing.Span {
+	return eventsourcing.SpanFunc(func(*eventsourcing.Observation) {})
+}
+
+func (p *PrometheusExporter) Observe(o *eventsourcing.Observation) {
+	var eventName, eventVersion string
+	if o.EventType != nil {
+		eventName, eventVersion = string(o.EventType.Name), string(o.EventType.Version)
+	}
+	operation := labels("operation", string(o.Operation), "domain", string(o.Domain))
+
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	p.operations[labels("operation", string(o.Operation), "domain", string(o.Domain), "event_name", eventName, "event_version", eventVersion, "outcome", o.Outcome())]++
+
+	h, ok := p.durations[operation]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(p.buckets))}
+		p.durations[operation] = h
+	}
+	seconds := o.Duration.Seconds()
+	for i, bound := range p.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += seconds
+
+	if o.EventsReplayed > 0 {
+		p.replayed[operation] += float64(o.EventsReplayed)
+	}
+	if o.SnapshotHit != nil {
+		result := "miss"
+		if *o.SnapshotHit {
+			result = "hit"
+		}
+		p.snapshots[labels("operation", string(o.Operation), "domain", string(o.Domain), "result", result)]++
+	}
+}
+
+// ServeHTTP | 계측 정보를 Prometheus text format 으로 응답한다.
+func (p *PrometheusExporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = p.Write(w)
+}
+
+// Write | 계측 정보를 Prometheus text format 으로 쓴다.
+func (p *PrometheusExporter) Write(w io.Writer) error {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	var b strings.Builder
+	writeCounter(&b, "eventsourcing_operations_total", "Number of event sourcing operations.", p.operations)
+
+	b.WriteString("# HELP eventsourcing_operation_duration_seconds Duration of event sourcing operations.\n")
+	b.WriteString("# TYPE eventsourcing_operation_duration_seconds histogram\n")
+	for _, key := range sortedKeys(p.durations) {
+		h := p.durations[key]
+		var cumulative uint64
+		for i, bound := range p.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&b, "eventsourcing_operation_duration_seconds_bucket{%s,le=\"%s\"} %d\n", key, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(&b, "eventsourcing_operation_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", key, h.count)
+		fmt.Fprintf(&b, "eventsourcing_operation_duration_seconds_sum{%s} %s\n", key, formatFloat(h.sum))
+		fmt.Fprintf(&b, "eventsourcing_operation_duration_seconds_count{%s} %d\n", key, h.count)
+	}
+
+	writeCounter(&b, "eventsourcing_events_replayed_total", "Number of events replayed.", p.replayed)
+	writeCounter(&b, "eventsourcing_snapshot_lookups_total", "Number of snapshot lookups by result.", p.snapshots)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeCounter(b *strings.Builder, name, help string, values map[string]float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(b, "# TYPE %s counter\n", name)
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(b, "%s{%s} %s\n", name, key, formatFloat(values[key]))
+	}
+}
+
+// labels | name, value 를 번갈아 받아서 `name="value",...` 로 만든다.
+func labels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=%s", pairs[i], escape(pairs[i+1])))
+	}
+	return strings.Join(parts, ",")
+}
+
+// escape | label 값의 역슬래시, 따옴표, 줄바꿈을 escape 한다.
+func escape(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
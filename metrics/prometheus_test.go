@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	es "eventsourcing"
+	"eventsourcing/example/currency"
+	"eventsourcing/example/storage"
+	"eventsourcing/manager"
+	"github.com/aws/smithy-go/ptr"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrometheusExporter(t *testing.T) {
+	exporter := NewPrometheusExporter(nil)
+	var spans []es.Operation
+	tracer := tracerFunc(func(op es.Operation) es.Span {
+		return es.SpanFunc(func(o *es.Observation) { spans = append(spans, o.Operation) })
+	})
+	m := manager.NewBaseManager[currency.State, currency.Request](
+		&es.Rule{AlwaysSnapshot: ptr.Bool(true)},
+		currency.Processor,
+		currency.Validator,
+		storage.NewCurrencyEventStorage(),
+		storage.NewCurrencySnapshotStorage(),
+		manager.WithInstrumentation[currency.State, currency.Request](es.Instrumentations(exporter, tracer)),
+	)
+
+	pk := es.PartitionKey("metrics")
+	_, _ = m.Put(pk, &currency.CreateAmountStateEvent, nil)
+	_, _ = m.Put(pk, &currency.AddAmountEvent, &currency.Request{Amount: 1})
+	_ = m.ApplyEvents(pk) // snapshot miss, 2개 replay
+	_, _ = m.Put(pk, &currency.AddAmountEvent, nil)
+	_ = m.ApplyEvents(pk) // snapshot hit, 실패한 event 는 replay 한 수에 세지 않음
+
+	server := httptest.NewServer(exporter)
+	defer server.Close()
+	res, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	text := string(body)
+
+	for _, want := range []string{
+		`eventsourcing_operations_total{operation="put",domain="currency",event_name="add_amount",event_version="v1",outcome="ok"} 2`,
+		`eventsourcing_operations_total{operation="apply_events",domain="currency",event_name="",event_version="",outcome="ok"} 1`,
+		`eventsourcing_operations_total{operation="apply_events",domain="currency",event_name="",event_version="",outcome="error"} 1`,
+		`eventsourcing_operation_duration_seconds_count{operation="put",domain="currency"} 3`,
+		`eventsourcing_events_replayed_total{operation="apply_events",domain="currency"} 2`,
+		`eventsourcing_events_replayed_total{operation="replay",domain="currency"} 2`,
+		`eventsourcing_snapshot_lookups_total{operation="apply_events",domain="currency",result="hit"} 1`,
+		`eventsourcing_snapshot_lookups_total{operation="apply_events",domain="currency",result="miss"} 1`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %s\n%s", want, text)
+		}
+	}
+	if len(spans) == 0 || spans[0] != es.OpPut {
+		t.Errorf("spans = %v", spans)
+	}
+}
+
+func TestPrometheusExporter_EventTypeCollision(t *testing.T) {
+	exporter := NewPrometheusExporter(nil)
+	// String() 은 둘 다 test_change_value_v1
+	for _, et := range []*es.EventType{
+		{Domain: "test", Name: "change_value", Version: "v1"},
+		{Domain: "test", Name: "change", Version: "value_v1"},
+	} {
+		exporter.Observe(&es.Observation{Operation: es.OpPut, Domain: et.Domain, EventType: et})
+	}
+
+	var b strings.Builder
+	if err := exporter.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`eventsourcing_operations_total{operation="put",domain="test",event_name="change_value",event_version="v1",outcome="ok"} 1`,
+		`eventsourcing_operations_total{operation="put",domain="test",event_name="change",event_version="value_v1",outcome="ok"} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("missing %s\n%s", want, b.String())
+		}
+	}
+}
+
+type tracerFunc func(op es.Operation) es.Span
+
+func (f tracerFunc) StartSpan(op es.Operation, _ es.PartitionKey, _ *es.EventType) es.Span {
+	return f(op)
+}
+func (f tracerFunc) Observe(*es.Observation) {}
@@ -0,0 +1,168 @@
+package eventsourcing
+
+import (
+	"sync"
+	"time"
+)
+
+// Process 실행에 공통 로직을 끼워넣는 Middleware 를 정의한다.
+//
+// type Middleware[S CommonState[R], R any]
+// - next Process 를 받아서, 앞뒤로 공통 로직을 수행하는 Process 를 돌려준다.
+// - Processor.Use 로 등록하며, 먼저 등록된 Middleware 가 바깥에서 감싼다.
+//   ex) Use(A, B) => A(B(process))
+// - Processor 는 기본으로 SkipAppliedEvent, TrackLastEvent 를 등록한다.
+//
+// 제공하는 Middleware
+// - SkipAppliedEvent : state 에 이미 적용된 이벤트는 Process 를 수행하지 않음
+// - TrackLastEvent : Process 수행 후 마지막 이벤트를 기록하고, 갱신되었는지 검사
+// - RecoverPanic : Process 의 panic 을 에러로 바꿈
+// - Logging : Process 수행 전후를 기록
+// - Timing : Process 수행 시간을 전달
+// - Metrics : EventType 별 수행/실패 횟수를 집계
+// - Instrument : Process 수행을 Instrumentation 으로 계측 (OpProcess)
+
+// Middleware | Process 를 감싸서 공통 로직을 적용하는 Func Type
+type Middleware[S CommonState[R], R any] func(next Process[S, R]) Process[S, R]
+
+// SkipAppliedEvent | state 최신 이벤트 번호가 요청온 event 번호보다 더 크거나 같다면, event 처리 무시
+func SkipAppliedEvent[S CommonState[R], R any]() Middleware[S, R] {
+	return func(next Process[S, R]) Process[S, R] {
+		return func(state *State[S, R], event *Event[R]) (*State[S, R], error) {
+			if state != nil {
+				if last := (*state.State()).GetLastEvent(); last != nil && event.EventNo <= last.EventNo {
+					return state, nil
+				}
+			}
+			return next(state, event)
+		}
+	}
+}
+
+// TrackLastEvent | state 가 LastEventSetter 를 구현하면 마지막 이벤트를 기록하고, 마지막 이벤트가 갱신되지 않았으면 에러를 돌려준다.
+// 마지막 이벤트가 갱신되지 않으면 다음 이벤트의 중복 검사가 깨지기 때문.
+func TrackLastEvent[S CommonState[R], R any]() Middleware[S, R] {
+	return func(next Process[S, R]) Process[S, R] {
+		return func(state *State[S, R], event *Event[R]) (*State[S, R], error) {
+			state, err := next(state, event)
+			if err != nil {
+				return nil, err
+			}
+			RecordLastEvent(state, event)
+			if err = CheckLastEvent(state, event); err != nil {
+				return nil, err
+			}
+			return state, nil
+		}
+	}
+}
+
+// RecoverPanic | Process 의 panic 을 에러로 바꾼다. Manager 의 replay 는 이 에러를 CommandError 로 돌려준다.
+func RecoverPanic[S CommonState[R], R any]() Middleware[S, R] {
+	return func(next Process[S, R]) Process[S, R] {
+		return func(state *State[S, R], event *Event[R]) (result *State[S, R], err error) {
+			defer HandleError(&err)
+			return next(state, event)
+		}
+	}
+}
+
+// Logging | Process 수행 전후를 logger 에 Debug 로 남기고, 실패하면 Error 로 남긴다.
+func Logging[S CommonState[R], R any](logger Logger) Middleware[S, R] {
+	return func(next Process[S, R]) Process[S, R] {
+		return func(state *State[S, R], event *Event[R]) (*State[S, R], error) {
+			fields := EventFields(event)
+			logger.Log(LevelDebug, "process start", fields...)
+			state, err := next(state, event)
+			if err != nil {
+				logger.Log(LevelError, "process failed", append(fields, ErrorFields(err)...)...)
+				return nil, err
+			}
+			logger.Log(LevelDebug, "process end", fields...)
+			return state, nil
+		}
+	}
+}
+
+// Timing | Process 수행 시간을 observe 로 전달한다. 실패하거나 panic 이 발생해도 전달한다.
+func Timing[S CommonState[R], R any](observe func(event *Event[R], elapsed time.Duration)) Middleware[S, R] {
+	return func(next Process[S, R]) Process[S, R] {
+		return func(state *State[S, R], event *Event[R]) (*State[S, R], error) {
+			start := time.Now()
+			defer func() {
+				observe(event, time.Since(start))
+			}()
+			return next(state, event)
+		}
+	}
+}
+
+// ProcessMetrics | EventType 별 Process 수행 횟수와 실패(에러, panic) 횟수
+type ProcessMetrics struct {
+	processed map[EventTypeKey]int
+	failed    map[EventTypeKey]int
+	locker    sync.Mutex
+}
+
+func NewProcessMetrics() *ProcessMetrics {
+	return &ProcessMetrics{
+		processed: make(map[EventTypeKey]int),
+		failed:    make(map[EventTypeKey]int),
+	}
+}
+
+// Processed | EventType 별 수행 횟수, 실패도 포함
+func (m *ProcessMetrics) Processed() map[EventTypeKey]int {
+	return m.copy(m.processed)
+}
+
+// Failed | EventType 별 실패 횟수
+func (m *ProcessMetrics) Failed() map[EventTypeKey]int {
+	return m.copy(m.failed)
+}
+
+func (m *ProcessMetrics) record(et *EventType, failed bool) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	m.processed[et.Key()]++
+	if failed {
+		m.failed[et.Key()]++
+	}
+}
+
+func (m *ProcessMetrics) copy(counts map[EventTypeKey]int) map[EventTypeKey]int {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	copied := make(map[EventTypeKey]int, len(counts))
+	for k, v := range counts {
+		copied[k] = v
+	}
+	return copied
+}
+
+// Metrics | Process 수행 횟수와 실패 횟수를 metrics 에 집계한다.
+func Metrics[S CommonState[R], R any](metrics *ProcessMetrics) Middleware[S, R] {
+	return func(next Process[S, R]) Process[S, R] {
+		return func(state *State[S, R], event *Event[R]) (*State[S, R], error) {
+			failed := true
+			defer func() {
+				metrics.record(event.EventType, failed)
+			}()
+			state, err := next(state, event)
+			failed = err != nil
+			return state, err
+		}
+	}
+}
+
+// Instrument | Process 수행을 inst 로 계측한다. (OpProcess)
+func Instrument[S CommonState[R], R any](inst Instrumentation) Middleware[S, R] {
+	return func(next Process[S, R]) Process[S, R] {
+		return func(state *State[S, R], event *Event[R]) (result *State[S, R], err error) {
+			t := StartTracking(inst, OpProcess, event.PartitionKey, event.EventType)
+			defer func() { t.Finish(err) }()
+			defer HandleError(&err)
+			return next(state, event)
+		}
+	}
+}
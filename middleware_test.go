@@ -0,0 +1,72 @@
+package eventsourcing
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProcessor_Use(t *testing.T) {
+	p := NewProcessor[counterState, int]()
+	p.SetProcess(processTestEventType, countProcess)
+	var calls []string
+	trace := func(name string) Middleware[counterState, int] {
+		return func(next Process[counterState, int]) Process[counterState, int] {
+			return func(s *State[counterState, int], e *Event[int]) (*State[counterState, int], error) {
+				calls = append(calls, fmt.Sprintf("%s:%d", name, e.EventNo))
+				return next(s, e)
+			}
+		}
+	}
+	p.Use(trace("a"), trace("b"))
+	cmd, _ := p.GetProcess(processTestEventType)
+
+	one := 1
+	first := NewEvent[int]("pk", &processTestEventType, 1, &one)
+	state, _ := cmd(nil, first)
+	state, _ = cmd(state, first) // 기본 Middleware 가 중복을 건너뛰므로 a, b 도 호출되지 않음
+
+	if got := strings.Join(calls, ","); got != "a:1,b:1" {
+		t.Errorf("calls = %s, want a:1,b:1", got)
+	}
+	if state.State().Count != 1 {
+		t.Errorf("count = %d, want 1", state.State().Count)
+	}
+}
+
+func TestRecoverPanicAndMetrics(t *testing.T) {
+	p := NewProcessor[counterState, int]()
+	p.SetProcess(processTestEventType, func(s *State[counterState, int], e *Event[int]) (*State[counterState, int], error) {
+		if s == nil {
+			s = NewState[counterState, int](&counterState{})
+		}
+		s.State().Count += *e.Request // Request 가 nil 이면 panic
+		return s, nil
+	})
+	metrics := NewProcessMetrics()
+	var observed []time.Duration
+	p.Use(
+		Metrics[counterState, int](metrics),
+		Timing[counterState, int](func(e *Event[int], elapsed time.Duration) { observed = append(observed, elapsed) }),
+		RecoverPanic[counterState, int](),
+	)
+	cmd, _ := p.GetProcess(processTestEventType)
+
+	one := 1
+	state, err := cmd(nil, NewEvent[int]("pk", &processTestEventType, 1, &one))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cmd(state, NewEvent[int]("pk", &processTestEventType, 2, nil)); err == nil {
+		t.Error("panic must be returned as error")
+	}
+
+	et := processTestEventType.Key()
+	if processed, failed := metrics.Processed()[et], metrics.Failed()[et]; processed != 2 || failed != 1 {
+		t.Errorf("processed = %d, failed = %d, want 2, 1", processed, failed)
+	}
+	if len(observed) != 2 {
+		t.Errorf("len(observed) = %d, want 2", len(observed))
+	}
+}
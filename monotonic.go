@@ -0,0 +1,138 @@
+package eventsourcing
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/rs/xid"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Monotonic Event Id
+//
+// xid 는 초 단위로만 정렬되므로, 같은 초에 여러 프로세스에서 발급된 Id 의 순서를 보장하지 못한다.
+// MonotonicIdGenerator 는 ULID 와 같은 형식으로 Id 를 발급한다.
+// - 앞 10자리 : millisecond 단위 시간 (48 bit)
+// - 뒤 16자리 : 랜덤 값 (80 bit), 같은 millisecond 안에서는 이전 값에 1을 더해 단조 증가시킨다.
+// - Crockford base32 로 인코딩하므로, 문자열 정렬 순서가 발급 순서와 같다.
+
+const (
+	monotonicIdLength   = 26
+	monotonicTimeLength = 10
+	crockfordAlphabet   = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	maxMonotonicTime    = 1<<48 - 1
+)
+
+var (
+	_ IdGenerator = &MonotonicIdGenerator{}
+
+	ErrInvalidEventId = errors.New("invalid event id")
+)
+
+// MonotonicIdGenerator | millisecond 시간 + 단조 증가하는 랜덤 값으로 EventId 를 발급하는 IdGenerator
+type MonotonicIdGenerator struct {
+	clock   Clock
+	entropy io.Reader
+	lastMs  uint64
+	lastRnd [10]byte
+	locker  sync.Mutex
+}
+
+// NewMonotonicIdGenerator | clock 의 시간으로 Id 를 발급한다. clock 이 nil 이면 DefaultClock 을 사용한다.
+func NewMonotonicIdGenerator(clock Clock) *MonotonicIdGenerator {
+	if clock == nil {
+		clock = DefaultClock
+	}
+	return &MonotonicIdGenerator{clock: clock, entropy: rand.Reader}
+}
+
+// WithEntropy | 랜덤 값을 읽어올 reader 를 지정한다. 테스트에서 재현 가능한 Id 가 필요할 때 사용한다.
+func (g *MonotonicIdGenerator) WithEntropy(entropy io.Reader) *MonotonicIdGenerator {
+	g.entropy = entropy
+	return g
+}
+
+func (g *MonotonicIdGenerator) NewId() EventId {
+	g.locker.Lock()
+	defer g.locker.Unlock()
+
+	ms := uint64(g.clock.Now().UnixMilli())
+	if ms <= g.lastMs {
+		// 같은 millisecond 이거나 시간이 뒤로 간 경우, 이전 값에 1을 더해 순서를 보장한다
+		ms = g.lastMs
+		if !incrementRandom(&g.lastRnd) {
+			ms++ // 랜덤 값이 넘치면 다음 millisecond 로 넘긴다
+		}
+	} else if _, err := io.ReadFull(g.entropy, g.lastRnd[:]); err != nil {
+		panic(fmt.Sprintf("read entropy failed. %s", err))
+	}
+	g.lastMs = ms
+	return EventId(encodeMonotonicId(ms, g.lastRnd))
+}
+
+// MonotonicIdLowerBound | t 이후에 발급된 Id 보다 작거나 같은 Id, 시간으로 Id 범위를 조회할 때 사용한다.
+func MonotonicIdLowerBound(t time.Time) EventId {
+	return EventId(encodeMonotonicId(uint64(t.UnixMilli()), [10]byte{}))
+}
+
+// ParseEventIdTime | EventId 가 발급된 시간을 꺼낸다. MonotonicIdGenerator 와 XidGenerator 가 발급한 Id 를 지원한다.
+func ParseEventIdTime(id EventId) (time.Time, error) {
+	switch len(id) {
+	case monotonicIdLength:
+		var ms uint64
+		for _, c := range strings.ToUpper(string(id[:monotonicTimeLength])) {
+			i := strings.IndexRune(crockfordAlphabet, c)
+			if i < 0 {
+				return time.Time{}, fmt.Errorf("%w. id(%s)", ErrInvalidEventId, id)
+			}
+			ms = ms<<5 | uint64(i)
+		}
+		if ms > maxMonotonicTime {
+			return time.Time{}, fmt.Errorf("%w. id(%s)", ErrInvalidEventId, id)
+		}
+		return time.UnixMilli(int64(ms)).UTC(), nil
+	default:
+		x, err := xid.FromString(string(id))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w. id(%s)", ErrInvalidEventId, id)
+		}
+		return x.Time().UTC(), nil
+	}
+}
+
+// incrementRandom | 80 bit 랜덤 값에 1을 더한다. 넘치면 false
+func incrementRandom(rnd *[10]byte) bool {
+	for i := len(rnd) - 1; i >= 0; i-- {
+		rnd[i]++
+		if rnd[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeMonotonicId(ms uint64, rnd [10]byte) string {
+	var out [monotonicIdLength]byte
+	// 시간 48 bit -> 10자리 (앞 2 bit 는 0)
+	for i := monotonicTimeLength - 1; i >= 0; i-- {
+		out[i] = crockfordAlphabet[ms&0x1f]
+		ms >>= 5
+	}
+	// 랜덤 80 bit -> 16자리
+	var acc uint64
+	var bits uint
+	pos := monotonicTimeLength
+	for _, b := range rnd {
+		acc = acc<<8 | uint64(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[pos] = crockfordAlphabet[(acc>>bits)&0x1f]
+			pos++
+		}
+	}
+	return string(out[:])
+}
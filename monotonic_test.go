@@ -0,0 +1,73 @@
+package eventsourcing
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestMonotonicIdGenerator(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(now)
+	gen := NewMonotonicIdGenerator(clock)
+
+	var prev EventId
+	for i := 0; i < 1000; i++ {
+		if i == 500 {
+			clock.Set(now.Add(-1 * time.Second)) // 시간이 뒤로 가도 순서는 유지되어야 함
+		}
+		id := gen.NewId()
+		if len(id) != monotonicIdLength {
+			t.Fatalf("len(%s) = %d, want %d", id, len(id), monotonicIdLength)
+		}
+		if id <= prev {
+			t.Fatalf("id is not increased. prev(%s), id(%s)", prev, id)
+		}
+		prev = id
+	}
+
+	clock.Set(now.Add(1500 * time.Millisecond))
+	id := gen.NewId()
+	at, err := ParseEventIdTime(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !at.Equal(clock.Now()) {
+		t.Errorf("ParseEventIdTime(%s) = %s, want %s", id, at, clock.Now())
+	}
+	if bound := MonotonicIdLowerBound(clock.Now()); bound > id || bound <= prev {
+		t.Errorf("MonotonicIdLowerBound() = %s, want between %s and %s", bound, prev, id)
+	}
+}
+
+func TestMonotonicIdGeneratorOverflow(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	gen := NewMonotonicIdGenerator(NewFakeClock(now)).WithEntropy(bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)))
+
+	first := gen.NewId() // 랜덤 값이 최대값
+	second := gen.NewId()
+	if second <= first {
+		t.Fatalf("id is not increased. first(%s), second(%s)", first, second)
+	}
+	at, err := ParseEventIdTime(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(time.Millisecond); !at.Equal(want) {
+		t.Errorf("ParseEventIdTime(%s) = %s, want %s", second, at, want)
+	}
+}
+
+func TestParseEventIdTime(t *testing.T) {
+	if _, err := ParseEventIdTime("invalid"); err == nil {
+		t.Error("ParseEventIdTime(invalid) error is nil")
+	}
+	id := XidGenerator{}.NewId()
+	at, err := ParseEventIdTime(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(at); d < 0 || d > time.Minute {
+		t.Errorf("ParseEventIdTime(%s) = %s", id, at)
+	}
+}
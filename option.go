@@ -0,0 +1,140 @@
+package eventsourcing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Put 옵션을 정의한다.
+//
+// Event 를 저장할 때 Request 외에 함께 기록할 정보(metadata)를 PutOption 으로 넘긴다.
+// - CorrelationId : 같은 흐름으로 이어지는 event 들이 공유하는 id, 지정하지 않으면 흐름의 시작으로 보고 자신의 EventId 를 사용
+// - CausationId : 이 event 를 일으킨 event 의 id
+// - Actor : event 를 요청한 주체
+// - Metadata : 그 외 custom header
+// - IdempotencyKey : 재시도된 Put 을 구분하는 키, Rule.IdempotencyWindow 안에 같은 키로 저장된 event 가 있으면 새로 저장하지 않는다
+//   같은 키를 다른 EventType 이나 Request 로 다시 쓰면 ErrIdempotencyKeyReused
+//
+// 하나의 event 가 다른 event 를 일으키는 경우 CausedBy 를 사용하면,
+// CausationId 를 원인 event 로 채우고 CorrelationId, Actor, Metadata 를 이어받는다.
+// 직접 지정한 값은 이어받은 값보다 우선한다.
+
+// ErrIdempotencyKeyReused | 멱등키가 다른 EventType 이나 Request 로 다시 사용됨
+var ErrIdempotencyKeyReused = errors.New("idempotency key is reused with a different event")
+
+// PutOptions | Put 할 때 Event 에 함께 기록할 정보
+type PutOptions struct {
+	CorrelationId  string
+	CausationId    EventId
+	Actor          Actor
+	Metadata       map[string]string
+	IdempotencyKey string
+}
+
+// PutOption | PutOptions 를 채우는 옵션
+type PutOption func(o *PutOptions)
+
+// NewPutOptions | opts 를 적용한 PutOptions 를 만든다.
+func NewPutOptions(opts ...PutOption) *PutOptions {
+	o := &PutOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithCorrelationId | CorrelationId 를 지정한다.
+func WithCorrelationId(id string) PutOption {
+	return func(o *PutOptions) {
+		o.CorrelationId = id
+	}
+}
+
+// WithCausationId | CausationId 를 지정한다.
+func WithCausationId(id EventId) PutOption {
+	return func(o *PutOptions) {
+		o.CausationId = id
+	}
+}
+
+// WithActor | Actor 를 지정한다.
+func WithActor(actor Actor) PutOption {
+	return func(o *PutOptions) {
+		o.Actor = actor
+	}
+}
+
+// WithMetadata | custom header 를 추가한다.
+func WithMetadata(key, value string) PutOption {
+	return func(o *PutOptions) {
+		if o.Metadata == nil {
+			o.Metadata = make(map[string]string)
+		}
+		o.Metadata[key] = value
+	}
+}
+
+// WithIdempotencyKey | 멱등키를 지정한다. 같은 partition 에 같은 키로 저장된 event 가 있으면 그 event 를 돌려주고 새로 저장하지 않는다.
+func WithIdempotencyKey(key string) PutOption {
+	return func(o *PutOptions) {
+		o.IdempotencyKey = key
+	}
+}
+
+// CausedBy | cause 가 일으킨 event 로 기록한다. 직접 지정하지 않은 CorrelationId, Actor, Metadata 는 cause 에서 이어받는다.
+func CausedBy[R any](cause *Event[R]) PutOption {
+	return func(o *PutOptions) {
+		o.CausationId = cause.EventId
+		if o.CorrelationId == "" {
+			o.CorrelationId = cause.CorrelationId
+			if o.CorrelationId == "" {
+				o.CorrelationId = string(cause.EventId)
+			}
+		}
+		if o.Actor == "" {
+			o.Actor = cause.Actor
+		}
+		for k, v := range cause.Metadata {
+			if _, ok := o.Metadata[k]; !ok {
+				WithMetadata(k, v)(o)
+			}
+		}
+	}
+}
+
+// ApplyPutOptions | PutOptions 를 Event 에 기록한다. CorrelationId 가 없으면 Event 자신의 EventId 를 사용한다.
+func ApplyPutOptions[R any](e *Event[R], o *PutOptions) {
+	e.CorrelationId = o.CorrelationId
+	if e.CorrelationId == "" {
+		e.CorrelationId = string(e.EventId)
+	}
+	e.CausationId = o.CausationId
+	e.Actor = o.Actor
+	e.Metadata = o.Metadata
+	e.IdempotencyKey = o.IdempotencyKey
+}
+
+// MatchIdempotentEvent | 멱등키로 찾은 stored 가 et, req 로 다시 요청한 event 와 같은지 확인한다. 다르면 ErrIdempotencyKeyReused
+// Request 를 읽을 수 없는(Shredded) event 는 EventType 만 비교한다.
+func MatchIdempotentEvent[R any](stored *Event[R], et *EventType, req *R) error {
+	if stored.EventType.Key() != et.Key() {
+		return fmt.Errorf("%w. key(%s), eventType(%s), stored(%s)", ErrIdempotencyKeyReused, stored.IdempotencyKey, et, stored.EventType)
+	}
+	if stored.Shredded() {
+		return nil
+	}
+	x, err := json.Marshal(stored.Request)
+	if err != nil {
+		return err
+	}
+	y, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(x, y) {
+		return fmt.Errorf("%w. key(%s), request is different", ErrIdempotencyKeyReused, stored.IdempotencyKey)
+	}
+	return nil
+}
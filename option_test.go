@@ -0,0 +1,40 @@
+package eventsourcing
+
+import (
+	"testing"
+)
+
+func TestApplyPutOptions(t *testing.T) {
+	et := &EventType{Domain: "test", Name: "test", Version: "v1"}
+	gen := NewSequenceIdGenerator("id-")
+
+	// 흐름의 시작 event 는 자신의 id 를 CorrelationId 로 사용
+	first := NewEventWith[string](gen, DefaultClock, "pk", et, 1, nil)
+	ApplyPutOptions(first, NewPutOptions(WithActor("user-1"), WithMetadata("trace", "t-1")))
+	if first.CorrelationId != string(first.EventId) {
+		t.Errorf("first.CorrelationId = %s, want %s", first.CorrelationId, first.EventId)
+	}
+
+	// first 가 일으킨 event 는 CorrelationId, Actor, Metadata 를 이어받음
+	second := NewEventWith[string](gen, DefaultClock, "other", et, 1, nil)
+	ApplyPutOptions(second, NewPutOptions(WithMetadata("trace", "t-2"), CausedBy(first)))
+	if second.CausationId != first.EventId {
+		t.Errorf("second.CausationId = %s, want %s", second.CausationId, first.EventId)
+	}
+	if second.CorrelationId != first.CorrelationId {
+		t.Errorf("second.CorrelationId = %s, want %s", second.CorrelationId, first.CorrelationId)
+	}
+	if second.Actor != "user-1" {
+		t.Errorf("second.Actor = %s, want user-1", second.Actor)
+	}
+	if second.Metadata["trace"] != "t-2" { // 직접 지정한 값이 우선
+		t.Errorf("second.Metadata[trace] = %s, want t-2", second.Metadata["trace"])
+	}
+
+	// 이어지는 event 도 같은 CorrelationId 를 가짐
+	third := NewEventWith[string](gen, DefaultClock, "pk", et, 2, nil)
+	ApplyPutOptions(third, NewPutOptions(CausedBy(second), WithActor("saga")))
+	if third.CorrelationId != first.CorrelationId || third.CausationId != second.EventId || third.Actor != "saga" {
+		t.Errorf("third = %s", JsonString(third))
+	}
+}
@@ -1,6 +1,10 @@
 package eventsourcing
 
-import "sync"
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
 
 // Event Sourcing 에서 사용할 Process 인터페이스와 구조체를 정의한다.
 //
@@ -14,52 +18,112 @@ import "sync"
 //   2) *Event[R] : State 에 반영할 이벤트
 // - 리턴 설명
 //   1) *State[S, R] : State 에 Event 를 반영한 후 변한 State
+//   2) error : Event 를 반영할 수 없을 때의 에러 (ex. request 가 비어있음), replay 는 이 에러를 어떤 event 에서 실패했는지 담아서 CommandError 로 돌려준다.
+//
+// type PanicProcess[S CommonState[R], R any]
+// - 에러를 리턴하지 않고 panic 하는 기존 방식의 Process
+// - AdaptPanicProcess 로 panic 을 에러로 바꾸는 Process 로 변환하여 사용한다.
 //
 // struct Processor
 // - 미리 정의한 Event Type 과 이에 맞춰 구현한 Process 를 Processor 에 Set 하고, Get 할 수 있게 만든 구조체
 // - Event Sourcing 의 각 기능은 이 Processor 를 주입받아서 유용하게 사용한다.
 // - SetProcess 설명
-//   1) Process 를 공통 로직을 태우게 wrapping 하여 저장
+//   1) EventType 과 Process 를 매핑하여 저장, EventType 은 Key 로 구분함
+// - Use 설명
+//   1) 모든 Process 에 적용할 Middleware 를 순서대로 추가 (middleware.go 참고)
+//   2) 기본으로 SkipAppliedEvent, TrackLastEvent 가 등록되어 있음
 // - GetProcess 설명
-//   2) 공통 로직을 포함시킨 Process 를 가져옴
+//   1) 등록된 Middleware 를 순서대로 감싼 Process 를 가져옴
+
+// ErrLastEventNotAdvanced | Process 를 수행한 State 의 마지막 이벤트가 수행한 이벤트가 아닐 때 사용하는 에러
+var ErrLastEventNotAdvanced = errors.New("last event is not advanced")
 
 // Process | Event 를 실제로 수행하는 Func Type
-type Process[S CommonState[R], R any] func(state *State[S, R], event *Event[R]) *State[S, R]
+type Process[S CommonState[R], R any] func(state *State[S, R], event *Event[R]) (*State[S, R], error)
+
+// PanicProcess | 에러를 panic 으로 알리는 Process Func Type
+type PanicProcess[S CommonState[R], R any] func(state *State[S, R], event *Event[R]) *State[S, R]
+
+// AdaptPanicProcess | PanicProcess 의 panic 을 에러로 돌려주는 Process 로 변환
+func AdaptPanicProcess[S CommonState[R], R any](cmd PanicProcess[S, R]) Process[S, R] {
+	return func(state *State[S, R], event *Event[R]) (next *State[S, R], err error) {
+		defer HandleError(&err)
+		return cmd(state, event), nil
+	}
+}
 
 // Processor | EventType 과 매핑되어 있는 Process 를 관리
 type Processor[S CommonState[R], R any] struct {
-	mapper   map[string]Process[S, R] // key : event type, value : process
-	rwLocker sync.RWMutex
+	mapper      map[EventTypeKey]Process[S, R] // key : event type, value : process
+	middlewares []Middleware[S, R]             // 먼저 등록된 Middleware 가 바깥에서 감쌈
+	rwLocker    sync.RWMutex
 }
 
 func NewProcessor[S CommonState[R], R any]() *Processor[S, R] {
 	return &Processor[S, R]{
-		mapper:   make(map[string]Process[S, R]),
-		rwLocker: sync.RWMutex{},
+		mapper:      make(map[EventTypeKey]Process[S, R]),
+		middlewares: []Middleware[S, R]{SkipAppliedEvent[S, R](), TrackLastEvent[S, R]()},
+		rwLocker:    sync.RWMutex{},
 	}
 }
 
 // SetProcess | EventType 과 Process 를 설정하기
-func (c Processor[S, R]) SetProcess(et EventType, cmd Process[S, R]) {
+func (c *Processor[S, R]) SetProcess(et EventType, cmd Process[S, R]) {
 	c.rwLocker.Lock()
 	defer c.rwLocker.Unlock()
+	c.mapper[et.Key()] = cmd
+}
 
-	// AOP 를 못하니까... Process 를 다시 Process 로 감싸서 공통 로직을 적용시킨다.
-	wrapped := func(state *State[S, R], event *Event[R]) *State[S, R] {
-		if state != nil {
-			if event.EventNo <= (*state.State()).GetLastEvent().EventNo {
-				return state // state 최신 이벤트 번호가 요청온 event 번호보다 더 크거나 같다면, event 처리 무시
-			}
-		}
-		return cmd(state, event)
-	}
-	c.mapper[et.String()] = wrapped
+// HasProcess | EventType 에 Process 가 설정되어 있는지 여부
+func (c *Processor[S, R]) HasProcess(et EventType) bool {
+	c.rwLocker.RLock()
+	defer c.rwLocker.RUnlock()
+	_, ok := c.mapper[et.Key()]
+	return ok
 }
 
-// GetProcess | EventType 으로 Process 를 가져오기
-func (c Processor[S, R]) GetProcess(et EventType) (cmd Process[S, R], ok bool) {
+// Use | 모든 Process 에 적용할 Middleware 를 추가하기, 이미 등록된 Middleware 의 안쪽에서 수행된다.
+func (c *Processor[S, R]) Use(middlewares ...Middleware[S, R]) {
+	c.rwLocker.Lock()
+	defer c.rwLocker.Unlock()
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// GetProcess | EventType 으로 Middleware 를 적용한 Process 를 가져오기
+func (c *Processor[S, R]) GetProcess(et EventType) (cmd Process[S, R], ok bool) {
 	c.rwLocker.RLock()
 	defer c.rwLocker.RUnlock()
-	cmd, ok = c.mapper[et.String()]
-	return cmd, ok
+	cmd, ok = c.mapper[et.Key()]
+	if !ok {
+		return nil, false
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		cmd = c.middlewares[i](cmd)
+	}
+	return cmd, true
+}
+
+// RecordLastEvent | state 가 LastEventSetter 를 구현하면 event 를 마지막 이벤트로 기록한다.
+func RecordLastEvent[S CommonState[R], R any](state *State[S, R], event *Event[R]) {
+	if state == nil || state.State() == nil {
+		return
+	}
+	if setter, ok := any(state.State()).(LastEventSetter[R]); ok {
+		setter.SetLastEvent(event)
+	}
+}
+
+// CheckLastEvent | event 를 수행한 state 의 마지막 이벤트가 event 로 갱신되었는지 검사한다.
+func CheckLastEvent[S CommonState[R], R any](state *State[S, R], event *Event[R]) error {
+	if state == nil || state.State() == nil {
+		return fmt.Errorf("%w. state is nil", ErrLastEventNotAdvanced)
+	}
+	last := (*state.State()).GetLastEvent()
+	if last == nil {
+		return fmt.Errorf("%w. last event is nil", ErrLastEventNotAdvanced)
+	}
+	if last.EventNo != event.EventNo {
+		return fmt.Errorf("%w. lastEventNo(%d), eventNo(%d)", ErrLastEventNotAdvanced, last.EventNo, event.EventNo)
+	}
+	return nil
 }
@@ -0,0 +1,104 @@
+package eventsourcing
+
+import (
+	"errors"
+	"testing"
+)
+
+var processTestEventType = EventType{Domain: "test", Name: "process", Version: "v1"}
+
+var errNilRequest = errors.New("request is nil")
+
+type counterState struct {
+	Count     int         `json:"count"`
+	LastEvent *Event[int] `json:"lastEvent"`
+}
+
+func (s counterState) GetPartitionKey() PartitionKey { return "pk" }
+func (s counterState) GetLastEvent() *Event[int]     { return s.LastEvent }
+func (s counterState) String() string                { return JsonString(s) }
+func (s *counterState) SetLastEvent(e *Event[int])   { s.LastEvent = e }
+
+// countProcess | Request 만큼 Count 를 더한다. LastEvent 는 기록하지 않음
+func countProcess(s *State[counterState, int], e *Event[int]) (*State[counterState, int], error) {
+	if e.Request == nil {
+		return nil, errNilRequest
+	}
+	if s == nil {
+		s = NewState[counterState, int](&counterState{})
+	}
+	s.State().Count += *e.Request
+	return s, nil
+}
+
+func TestProcessor_RecordLastEvent(t *testing.T) {
+	p := NewProcessor[counterState, int]()
+	p.SetProcess(processTestEventType, countProcess)
+	cmd, _ := p.GetProcess(processTestEventType)
+
+	one, two := 1, 2
+	first := NewEvent[int]("pk", &processTestEventType, 1, &one)
+	second := NewEvent[int]("pk", &processTestEventType, 2, &two)
+	state, _ := cmd(nil, first)
+	state, _ = cmd(state, second)
+	state, _ = cmd(state, first) // 이미 적용된 이벤트는 건너뜀
+
+	if state.State().Count != 3 {
+		t.Errorf("count = %d, want 3", state.State().Count)
+	}
+	if last := state.State().LastEvent; last != second {
+		t.Errorf("last event = %v, want %v", last, second)
+	}
+}
+
+func TestProcessor_CheckLastEvent(t *testing.T) {
+	// LastEventSetter 를 구현하지 않고, Process 에서 LastEvent 를 기록하지도 않음
+	p := NewProcessor[cloneTestState, int]()
+	p.SetProcess(processTestEventType, func(s *State[cloneTestState, int], e *Event[int]) (*State[cloneTestState, int], error) {
+		return NewState[cloneTestState, int](&cloneTestState{PartitionKey: e.PartitionKey}), nil
+	})
+	cmd, _ := p.GetProcess(processTestEventType)
+
+	_, err := cmd(nil, NewEvent[int]("pk", &processTestEventType, 1, nil))
+	if !errors.Is(err, ErrLastEventNotAdvanced) {
+		t.Errorf("err = %v, want ErrLastEventNotAdvanced", err)
+	}
+}
+
+func TestReplayEventsWithState_Error(t *testing.T) {
+	p := NewProcessor[counterState, int]()
+	p.SetProcess(processTestEventType, countProcess)
+
+	one := 1
+	init, _ := ReplayEventsWithoutState[counterState, int](p, nil, NewEvent[int]("pk", &processTestEventType, 1, &one))
+	state, err := ReplayEventsWithState[counterState, int](p, init,
+		NewEvent[int]("pk", &processTestEventType, 2, &one),
+		NewEvent[int]("pk", &processTestEventType, 3, nil), // 실패
+		NewEvent[int]("pk", &processTestEventType, 4, &one),
+	)
+	var esErr *EventSourceError
+	if state != nil || !errors.As(err, &esErr) || esErr.EventNo() != 3 || !errors.Is(err, errNilRequest) {
+		t.Errorf("state = %v, err = %v", state, err)
+	}
+	if init.State().Count != 1 {
+		t.Errorf("init state is changed. %s", init)
+	}
+}
+
+func TestAdaptPanicProcess(t *testing.T) {
+	p := NewProcessor[counterState, int]()
+	p.SetProcess(processTestEventType, AdaptPanicProcess[counterState, int](func(s *State[counterState, int], e *Event[int]) *State[counterState, int] {
+		s = NewState[counterState, int](&counterState{})
+		s.State().Count += *e.Request // Request 가 nil 이면 panic
+		return s
+	}))
+	cmd, _ := p.GetProcess(processTestEventType)
+
+	one := 1
+	if state, err := cmd(nil, NewEvent[int]("pk", &processTestEventType, 1, &one)); err != nil || state.State().Count != 1 {
+		t.Errorf("state = %v, err = %v", state, err)
+	}
+	if state, err := cmd(nil, NewEvent[int]("pk", &processTestEventType, 1, nil)); err == nil {
+		t.Errorf("state = %v, want panic as error", state)
+	}
+}
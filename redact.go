@@ -0,0 +1,222 @@
+package eventsourcing
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Redaction
+//
+// State, Event 의 Request 에는 지갑 주소, 소유자 키처럼 로그나 에러 메세지에 남으면 안 되는 값이 있다.
+// struct field 에 `es:"redact"` tag 를 달면 JsonString, State.String(JsonString 으로 구현한 경우), Event.Redacted, 에러 메세지에서 값을 가린다.
+//
+//	type Request struct {
+//		Owner string `json:"owner" es:"redact"`
+//	}
+//
+// - 가린 값은 RedactedValue 로 바뀐다. (nil, 빈 값도 가린다)
+// - json.Marshaler 를 구현한 타입의 안쪽은 검사하지 않는다.
+// - 가릴 field 가 없는 타입은 json.Marshal 결과를 그대로 사용한다.
+// - storage 에 저장하는 값은 가리지 않는다.
+
+// RedactTag | redaction 을 지정하는 struct tag 의 key 와 value
+const (
+	RedactTagKey   = "es"
+	RedactTagValue = "redact"
+)
+
+// RedactedValue | 가린 값 대신 들어가는 문자열
+const RedactedValue = "[REDACTED]"
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	redactTypeCache   sync.Map // key : reflect.Type, value : bool, 가릴 field 가 있는지 여부
+)
+
+// RedactedJson | v 를 json 으로 변환하되, `es:"redact"` field 의 값은 RedactedValue 로 가린다.
+func RedactedJson(v any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || v == nil || !hasRedactField(reflect.TypeOf(v)) {
+		return b, err
+	}
+
+	// json 을 다시 풀어서, 값의 타입을 따라가며 가릴 field 를 바꾼다
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var tree any
+	if err = decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return json.Marshal(redactTree(reflect.ValueOf(v), tree))
+}
+
+// hasRedactField | t 안에 가릴 field 가 있는지 확인한다. 결과는 타입 별로 저장한다.
+func hasRedactField(t reflect.Type) bool {
+	if cached, ok := redactTypeCache.Load(t); ok {
+		return cached.(bool)
+	}
+	result := scanRedactField(t, map[reflect.Type]bool{})
+	redactTypeCache.Store(t, result)
+	return result
+}
+
+func scanRedactField(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return false // 재귀 타입은 처음 방문한 곳에서 판단
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	if t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface && (t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return scanRedactField(t.Elem(), visiting)
+	case reflect.Interface:
+		return true // 실제 값을 알 수 없으므로 값을 따라가며 확인
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() && !f.Anonymous {
+				continue
+			}
+			if isRedactField(f) || scanRedactField(f.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isRedactField(f reflect.StructField) bool {
+	for _, option := range strings.Split(f.Tag.Get(RedactTagKey), ",") {
+		if option == RedactTagValue {
+			return true
+		}
+	}
+	return false
+}
+
+// redactTree | json 을 풀어둔 tree 에서 v 의 가릴 field 를 바꾼다.
+func redactTree(v reflect.Value, tree any) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return tree
+		}
+		v = v.Elem()
+	}
+	if t := v.Type(); t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return tree
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if object, ok := tree.(map[string]any); ok {
+			redactStruct(v, object)
+		}
+	case reflect.Slice, reflect.Array:
+		if list, ok := tree.([]any); ok {
+			for i := 0; i < v.Len() && i < len(list); i++ {
+				list[i] = redactTree(v.Index(i), list[i])
+			}
+		}
+	case reflect.Map:
+		if object, ok := tree.(map[string]any); ok {
+			iter := v.MapRange()
+			for iter.Next() {
+				key, ok := mapKeyString(iter.Key())
+				if !ok {
+					continue
+				}
+				if child, exists := object[key]; exists {
+					object[key] = redactTree(iter.Value(), child)
+				}
+			}
+		}
+	}
+	return tree
+}
+
+// redactStruct | struct 의 field 를 json 이름으로 찾아 바꾼다. 이름 없이 embed 된 struct 의 field 는 같은 object 에 있다.
+func redactStruct(v reflect.Value, object map[string]any) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, skip := jsonFieldName(f)
+		if skip {
+			continue
+		}
+		if name == "" { // 이름 없이 embed 된 struct
+			embedded := v.Field(i)
+			for embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					break
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				redactStruct(embedded, object)
+			}
+			continue
+		}
+		child, exists := object[name]
+		if !exists {
+			continue
+		}
+		if isRedactField(f) {
+			object[name] = RedactedValue
+			continue
+		}
+		object[name] = redactTree(v.Field(i), child)
+	}
+}
+
+// jsonFieldName | encoding/json 이 사용하는 field 이름, 이름 없이 embed 된 struct 는 빈 값
+func jsonFieldName(f reflect.StructField) (name string, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	if f.Anonymous && name == "" {
+		t := f.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			return "", false
+		}
+	}
+	if !f.IsExported() {
+		return "", true
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, false
+}
+
+// mapKeyString | encoding/json 이 map key 를 문자열로 바꾸는 규칙
+func mapKeyString(k reflect.Value) (string, bool) {
+	if k.Kind() == reflect.String {
+		return k.String(), true
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err == nil
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b, _ := json.Marshal(k.Interface())
+		return string(b), true
+	}
+	return "", false
+}
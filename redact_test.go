@@ -0,0 +1,85 @@
+package eventsourcing
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type redactCredential struct {
+	Secret string `json:"secret" es:"redact"`
+	Label  string `json:"label"`
+}
+
+type redactRequest struct {
+	redactCredential
+	Owner   string                      `json:"owner" es:"redact"`
+	Amount  int                         `json:"amount"`
+	Keys    []redactCredential          `json:"keys"`
+	ByName  map[string]redactCredential `json:"byName"`
+	Nested  *redactCredential           `json:"nested,omitempty"`
+	Payload any                         `json:"payload"`
+}
+
+type redactState struct {
+	Wallet    string                `json:"wallet" es:"redact"`
+	LastEvent *Event[redactRequest] `json:"lastEvent"`
+}
+
+func (s redactState) GetPartitionKey() PartitionKey       { return "pk" }
+func (s redactState) GetLastEvent() *Event[redactRequest] { return s.LastEvent }
+func (s redactState) String() string                      { return JsonString(s) }
+
+func newRedactRequest() *redactRequest {
+	return &redactRequest{
+		redactCredential: redactCredential{Secret: "s-embedded", Label: "embedded"},
+		Owner:            "s-owner",
+		Amount:           10,
+		Keys:             []redactCredential{{Secret: "s-slice", Label: "slice"}},
+		ByName:           map[string]redactCredential{"a": {Secret: "s-map", Label: "map"}},
+		Nested:           &redactCredential{Secret: "s-pointer", Label: "pointer"},
+		Payload:          redactCredential{Secret: "s-interface", Label: "interface"},
+	}
+}
+
+func assertRedacted(t *testing.T, name, s string) {
+	t.Helper()
+	if strings.Contains(s, "s-") {
+		t.Errorf("%s leaks a redacted value. %s", name, s)
+	}
+	if !strings.Contains(s, RedactedValue) {
+		t.Errorf("%s has no %s. %s", name, RedactedValue, s)
+	}
+}
+
+func TestJsonString_Redact(t *testing.T) {
+	s := JsonString(newRedactRequest())
+	assertRedacted(t, "JsonString", s)
+	for _, label := range []string{"embedded", "slice", "map", "pointer", "interface", `"amount":10`} {
+		if !strings.Contains(s, label) {
+			t.Errorf("JsonString lost %q. %s", label, s)
+		}
+	}
+
+	// 가릴 field 가 없는 타입은 그대로
+	if got := JsonString(map[string]int{"a": 1}); got != `{"a":1}` {
+		t.Errorf("JsonString = %s", got)
+	}
+}
+
+func TestRedact_EventAndErrors(t *testing.T) {
+	e := NewEvent[redactRequest]("pk", &processTestEventType, 3, newRedactRequest())
+	assertRedacted(t, "Event.Redacted", e.Redacted())
+	if e.String() != processTestEventType.String() {
+		t.Errorf("Event.String = %s, want the EventType name", e.String())
+	}
+
+	state := NewState[redactState, redactRequest](&redactState{Wallet: "s-wallet", LastEvent: e})
+	assertRedacted(t, "State.String", state.String())
+
+	cause := errors.New("cause")
+	if msg := NewCommandError(cause, "pk", e).Error(); strings.Contains(msg, "s-") {
+		t.Errorf("command error leaks a redacted value. %s", msg)
+	}
+	assertRedacted(t, "validate error", NewValidateError(cause, "pk", state).Error())
+}
@@ -0,0 +1,126 @@
+package eventsourcing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Event Type Registry
+//
+// Domain 의 EventType 을 한 곳에 선언하고 검사한다.
+// - EventType 은 EventTypeKey 로 구분하고, 같은 Key 를 두 번 등록하면 ErrDuplicateEventType
+// - EventType.String() 은 '_' 로 이어붙이므로 다른 Key 라도 같은 이름이 될 수 있다. 이름이 겹치면 ErrEventTypeCollision
+// - 저장된 이름(EventType.String())으로 등록된 EventType 을 찾을 수 있다. (역직렬화, 외부 메세지 등)
+// - CheckProcessor 로 등록된 모든 EventType 에 Process 가 설정되었는지 검사한다.
+// - Check 로 등록되지 않은 EventType 을 거절한다. (manager.WithRegistry)
+
+var (
+	ErrInvalidEventType      = errors.New("event type has empty domain, name or version")
+	ErrDuplicateEventType    = errors.New("event type is already registered")
+	ErrEventTypeCollision    = errors.New("event type name collides with another event type")
+	ErrUnregisteredEventType = errors.New("event type is not registered")
+)
+
+// EventTypeRegistry | Domain 의 EventType 목록
+type EventTypeRegistry struct {
+	types  []*EventType                // 등록 순서
+	byKey  map[EventTypeKey]*EventType // key : EventType.Key()
+	byName map[string]*EventType       // key : EventType.String()
+	locker sync.RWMutex
+}
+
+func NewEventTypeRegistry() *EventTypeRegistry {
+	return &EventTypeRegistry{
+		byKey:  make(map[EventTypeKey]*EventType),
+		byName: make(map[string]*EventType),
+	}
+}
+
+// Register | EventType 을 등록한다. 하나라도 등록할 수 없으면 아무것도 등록하지 않는다.
+func (r *EventTypeRegistry) Register(types ...*EventType) error {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	byKey := make(map[EventTypeKey]*EventType, len(types))
+	byName := make(map[string]*EventType, len(types))
+	for _, et := range types {
+		if et.Domain == "" || et.Name == "" || et.Version == "" {
+			return fmt.Errorf("%w. eventType(%s)", ErrInvalidEventType, et.String())
+		}
+		key, name := et.Key(), et.String()
+		if r.byKey[key] != nil || byKey[key] != nil {
+			return fmt.Errorf("%w. eventType(%s)", ErrDuplicateEventType, name)
+		}
+		if other := r.byName[name]; other != nil {
+			return fmt.Errorf("%w. eventType(%s), other(%+v)", ErrEventTypeCollision, name, other.Key())
+		}
+		if other := byName[name]; other != nil {
+			return fmt.Errorf("%w. eventType(%s), other(%+v)", ErrEventTypeCollision, name, other.Key())
+		}
+		byKey[key], byName[name] = et, et
+	}
+
+	for _, et := range types {
+		r.types = append(r.types, et)
+		r.byKey[et.Key()], r.byName[et.String()] = et, et
+	}
+	return nil
+}
+
+// MustRegister | Register 에 실패하면 panic, package 변수를 선언할 때 사용
+func (r *EventTypeRegistry) MustRegister(types ...*EventType) *EventTypeRegistry {
+	if err := r.Register(types...); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Get | Key 로 등록된 EventType 을 찾는다.
+func (r *EventTypeRegistry) Get(key EventTypeKey) (*EventType, bool) {
+	r.locker.RLock()
+	defer r.locker.RUnlock()
+	et, ok := r.byKey[key]
+	return et, ok
+}
+
+// Check | et 가 등록되어 있지 않으면 ErrUnregisteredEventType
+func (r *EventTypeRegistry) Check(et *EventType) error {
+	if et == nil {
+		return fmt.Errorf("%w. eventType(nil)", ErrUnregisteredEventType)
+	}
+	if _, ok := r.Get(et.Key()); !ok {
+		return fmt.Errorf("%w. eventType(%s)", ErrUnregisteredEventType, et)
+	}
+	return nil
+}
+
+// Lookup | 이름(EventType.String())으로 등록된 EventType 을 찾는다.
+func (r *EventTypeRegistry) Lookup(name string) (*EventType, bool) {
+	r.locker.RLock()
+	defer r.locker.RUnlock()
+	et, ok := r.byName[name]
+	return et, ok
+}
+
+// EventTypes | 등록된 EventType 을 등록 순서로 돌려준다.
+func (r *EventTypeRegistry) EventTypes() []*EventType {
+	r.locker.RLock()
+	defer r.locker.RUnlock()
+	return append([]*EventType(nil), r.types...)
+}
+
+// CheckProcessor | registry 에 등록된 모든 EventType 에 Process 가 설정되어 있는지 검사한다. 빠진 EventType 이 있으면 NoHasCommand 에러
+func CheckProcessor[S CommonState[R], R any](registry *EventTypeRegistry, processor *Processor[S, R]) error {
+	var missing []string
+	for _, et := range registry.EventTypes() {
+		if !processor.HasProcess(*et) {
+			missing = append(missing, et.String())
+		}
+	}
+	if len(missing) > 0 {
+		return newEventSourceError(NoHasCommand, nil, "no has command. eventTypes(%s)", strings.Join(missing, ", "))
+	}
+	return nil
+}
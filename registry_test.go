@@ -0,0 +1,57 @@
+package eventsourcing
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEventTypeRegistry(t *testing.T) {
+	// String() 이 "a_b_c_v1" 로 같은 두 EventType
+	left := &EventType{Domain: "a_b", Name: "c", Version: "v1"}
+	right := &EventType{Domain: "a", Name: "b_c", Version: "v1"}
+	other := &EventType{Domain: "a", Name: "d", Version: "v1"}
+
+	registry := NewEventTypeRegistry()
+	if err := registry.Register(left, other); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register(right); !errors.Is(err, ErrEventTypeCollision) {
+		t.Errorf("collision err = %v", err)
+	}
+	if err := registry.Register(&EventType{Domain: "a", Name: "d", Version: "v1", NeedLock: true}); !errors.Is(err, ErrDuplicateEventType) {
+		t.Errorf("duplicate err = %v", err)
+	}
+	if err := registry.Register(&EventType{Domain: "a", Name: "e"}); !errors.Is(err, ErrInvalidEventType) {
+		t.Errorf("invalid err = %v", err)
+	}
+	if len(registry.EventTypes()) != 2 {
+		t.Errorf("failed Register must not register. %s", JsonString(registry.EventTypes()))
+	}
+
+	if et, ok := registry.Lookup("a_b_c_v1"); !ok || et != left {
+		t.Errorf("Lookup = %v, %v", et, ok)
+	}
+	if et, ok := registry.Get(other.Key()); !ok || et != other {
+		t.Errorf("Get = %v, %v", et, ok)
+	}
+}
+
+func TestProcessor_KeyedByEventTypeKey(t *testing.T) {
+	left := EventType{Domain: "a_b", Name: "c", Version: "v1"}
+	right := EventType{Domain: "a", Name: "b_c", Version: "v1"}
+
+	p := NewProcessor[counterState, int]()
+	p.SetProcess(left, countProcess)
+	if p.HasProcess(right) {
+		t.Error("event types with the same String() must not share a process")
+	}
+
+	registry := NewEventTypeRegistry().MustRegister(&left)
+	if err := CheckProcessor[counterState, int](registry, p); err != nil {
+		t.Errorf("CheckProcessor = %v", err)
+	}
+	registry.MustRegister(&EventType{Domain: "a", Name: "d", Version: "v1"})
+	if err := CheckProcessor[counterState, int](registry, p); !errors.Is(err, ErrNoHasCommand) {
+		t.Errorf("missing process err = %v", err)
+	}
+}
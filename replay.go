@@ -38,12 +38,20 @@ func replayEvents[S CommonState[R], R any](
 ) (
 	*State[S, R], error,
 ) {
+	// Process 는 state 를 제자리에서 바꾸므로 복사본에 replay 한다
+	state, err := state.Clone()
+	if err != nil {
+		return nil, err
+	}
 	for _, e := range events {
 		cmd, ok := commander.GetProcess(*e.EventType)
 		if !ok {
 			return state, errors.New("not defined event")
 		}
-		state = cmd(state, e)
+		state, err = cmd(state, e)
+		if err != nil {
+			return nil, NewCommandError(err, e.PartitionKey, e) // 어떤 event 에서 실패했는지 담아서 돌려준다
+		}
 	}
 	return state, nil
 }
@@ -0,0 +1,105 @@
+package eventsourcing
+
+import (
+	"sort"
+	"time"
+)
+
+// Snapshot 보관 정책을 정의한다.
+//
+// StateSnapshotHistoryStorage 는 pk 마다 여러 snapshot 을 보관하므로, 무한히 쌓이지 않게 RetentionPolicy 로 정리한다.
+// - KeepLast : 최근 n 개의 snapshot 을 보관
+// - KeepDaily : 가장 최근 snapshot 의 날짜까지 n 일(달력 기준) 동안, 하루의 마지막 snapshot 을 하나씩 보관 (날짜는 snapshot 의 마지막 eventAt 의 UTC 기준)
+//   snapshot 이 없는 날도 n 일에 포함하므로, n 일보다 오래된 snapshot 은 개수와 관계없이 정리한다.
+// - KeepAll : 모두 보관
+// - Retentions : 여러 정책 중 하나라도 보관하는 snapshot 을 보관
+// KeepLast, KeepDaily 는 n 이 1 보다 작아도 방금 저장한 가장 최근 snapshot 은 보관한다.
+
+// SnapshotInfo | 보관중인 snapshot 의 정보
+type SnapshotInfo struct {
+	EventNo int       `json:"eventNo"` // snapshot 에 반영된 마지막 eventNo
+	EventAt time.Time `json:"eventAt"` // snapshot 에 반영된 마지막 eventAt
+}
+
+// RetentionPolicy | 보관할 snapshot 을 고르는 정책, snapshots 는 EventNo 오름차순이며 보관할 snapshot 을 같은 순서로 돌려준다.
+type RetentionPolicy interface {
+	Retain(snapshots []SnapshotInfo) []SnapshotInfo
+}
+
+// RetentionFunc | func 를 RetentionPolicy 로 사용
+type RetentionFunc func(snapshots []SnapshotInfo) []SnapshotInfo
+
+func (f RetentionFunc) Retain(snapshots []SnapshotInfo) []SnapshotInfo {
+	return f(snapshots)
+}
+
+// KeepAll | 모든 snapshot 을 보관
+func KeepAll() RetentionPolicy {
+	return RetentionFunc(func(snapshots []SnapshotInfo) []SnapshotInfo {
+		return snapshots
+	})
+}
+
+// KeepLast | 최근 n 개의 snapshot 을 보관, n 이 1 보다 작으면 가장 최근 snapshot 하나만 보관한다.
+func KeepLast(n int) RetentionPolicy {
+	if n < 1 {
+		n = 1 // 방금 저장한 snapshot 은 남긴다
+	}
+	return RetentionFunc(func(snapshots []SnapshotInfo) []SnapshotInfo {
+		if len(snapshots) <= n {
+			return snapshots
+		}
+		return snapshots[len(snapshots)-n:]
+	})
+}
+
+// KeepDaily | 가장 최근 snapshot 의 날짜까지 days 일 동안 하루의 마지막 snapshot 을 하나씩 보관, snapshot 이 없는 날도 days 에 포함한다.
+// days 가 1 보다 작으면 가장 최근 snapshot 하나만 보관한다.
+func KeepDaily(days int) RetentionPolicy {
+	if days < 1 {
+		days = 1 // 방금 저장한 snapshot 은 남긴다
+	}
+	return RetentionFunc(func(snapshots []SnapshotInfo) []SnapshotInfo {
+		if len(snapshots) == 0 {
+			return nil
+		}
+		newest := snapshots[len(snapshots)-1].EventAt.UTC().Truncate(24 * time.Hour)
+		oldest := newest.AddDate(0, 0, -(days - 1))
+		kept := make([]SnapshotInfo, 0, days)
+		var lastDay time.Time
+		for i := len(snapshots) - 1; i >= 0; i-- {
+			day := snapshots[i].EventAt.UTC().Truncate(24 * time.Hour)
+			if day.Before(oldest) {
+				break
+			}
+			if len(kept) > 0 && day.Equal(lastDay) {
+				continue // 같은 날의 더 이전 snapshot
+			}
+			kept = append(kept, snapshots[i])
+			lastDay = day
+		}
+		// 역순으로 모았으므로 오름차순으로 되돌린다
+		for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+			kept[i], kept[j] = kept[j], kept[i]
+		}
+		return kept
+	})
+}
+
+// Retentions | 여러 정책 중 하나라도 보관하는 snapshot 을 보관
+func Retentions(policies ...RetentionPolicy) RetentionPolicy {
+	return RetentionFunc(func(snapshots []SnapshotInfo) []SnapshotInfo {
+		keep := make(map[int]SnapshotInfo)
+		for _, p := range policies {
+			for _, info := range p.Retain(snapshots) {
+				keep[info.EventNo] = info
+			}
+		}
+		kept := make([]SnapshotInfo, 0, len(keep))
+		for _, info := range keep {
+			kept = append(kept, info)
+		}
+		sort.Slice(kept, func(i, j int) bool { return kept[i].EventNo < kept[j].EventNo })
+		return kept
+	})
+}
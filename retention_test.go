@@ -0,0 +1,80 @@
+package eventsourcing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	day := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := []SnapshotInfo{
+		{EventNo: 1, EventAt: day},
+		{EventNo: 2, EventAt: day.Add(1 * time.Hour)},
+		{EventNo: 3, EventAt: day.Add(25 * time.Hour)},
+		{EventNo: 4, EventAt: day.Add(49 * time.Hour)},
+		{EventNo: 5, EventAt: day.Add(50 * time.Hour)},
+	}
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		want   []int
+	}{
+		{"keep_all", KeepAll(), []int{1, 2, 3, 4, 5}},
+		{"keep_last", KeepLast(2), []int{4, 5}},
+		{"keep_last_over", KeepLast(10), []int{1, 2, 3, 4, 5}},
+		{"keep_last_zero", KeepLast(0), []int{5}},
+		{"keep_last_negative", KeepLast(-1), []int{5}},
+		{"keep_daily", KeepDaily(2), []int{3, 5}},
+		{"keep_daily_over", KeepDaily(10), []int{2, 3, 5}},
+		{"retentions", Retentions(KeepLast(2), KeepDaily(3)), []int{2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.Retain(snapshots)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Retain() = %s, want %v", JsonString(got), tt.want)
+			}
+			for i := range got {
+				if got[i].EventNo != tt.want[i] {
+					t.Errorf("Retain()[%d] = %d, want %d", i, got[i].EventNo, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRetentionPolicy_KeepDailyGap(t *testing.T) {
+	day := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := []SnapshotInfo{
+		{EventNo: 1, EventAt: day},
+		{EventNo: 2, EventAt: day.AddDate(0, 0, 1)},
+		{EventNo: 3, EventAt: day.AddDate(0, 0, 7)},
+		{EventNo: 4, EventAt: day.AddDate(0, 0, 9)},
+		{EventNo: 5, EventAt: day.AddDate(0, 0, 9).Add(time.Hour)},
+	}
+	tests := []struct {
+		name string
+		days int
+		want []int
+	}{
+		{"newest_day_only", 1, []int{5}},
+		{"skip_empty_days", 3, []int{3, 5}}, // 1/8 ~ 1/10, 1/9 에는 snapshot 이 없음
+		{"before_gap", 9, []int{2, 3, 5}},   // 1/2 ~ 1/10
+		{"all_days", 10, []int{1, 2, 3, 5}},
+		{"zero", 0, []int{5}},
+		{"negative", -1, []int{5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := KeepDaily(tt.days).Retain(snapshots)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Retain() = %s, want %v", JsonString(got), tt.want)
+			}
+			for i := range got {
+				if got[i].EventNo != tt.want[i] {
+					t.Errorf("Retain()[%d] = %d, want %d", i, got[i].EventNo, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package retry
+
+import (
+	"eventsourcing"
+	"sync"
+	"time"
+)
+
+// circuitOpenError | circuit 이 열려 호출하지 않았을 때의 에러, 바로 다시 시도하지 않도록 Retryable 은 false
+type circuitOpenError struct{}
+
+func (circuitOpenError) Error() string   { return "circuit is open" }
+func (circuitOpenError) Retryable() bool { return false }
+
+// ErrCircuitOpen | storage 가 계속 실패해서 호출을 막았을 때 돌려주는 에러
+var ErrCircuitOpen error = circuitOpenError{}
+
+// CircuitState | circuit breaker 의 상태
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // 정상, 호출을 허용
+	CircuitOpen                         // 연속으로 실패하여 호출을 막음
+	CircuitHalfOpen                     // OpenTimeout 이 지나 한 번의 시험 호출을 허용
+)
+
+// BreakerConfig | CircuitBreaker 설정
+type BreakerConfig struct {
+	FailureThreshold int                  // default 5, circuit 을 여는 연속 실패 횟수
+	OpenTimeout      time.Duration        // default 30s, circuit 을 연 뒤 시험 호출을 허용하기까지의 시간
+	Clock            eventsourcing.Clock  // default eventsourcing.DefaultClock
+	Logger           eventsourcing.Logger // default eventsourcing.NopLogger, circuit 이 열리면 Error, 닫히면 Info 로 남긴다.
+}
+
+// CircuitBreaker | storage 가 계속 실패하면 일정 시간 호출을 막아서, 장애 중인 storage 에 요청이 몰리지 않게 한다.
+// 여러 decorator 가 같은 storage 를 쓴다면 하나의 CircuitBreaker 를 함께 사용한다.
+type CircuitBreaker struct {
+	config   BreakerConfig
+	state    CircuitState
+	failures int       // 연속 실패 횟수
+	openedAt time.Time // circuit 을 연 시간
+	trying   bool      // half-open 상태에서 시험 호출 중인지 여부
+	locker   sync.Mutex
+}
+
+func NewCircuitBreaker(config BreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.Clock == nil {
+		config.Clock = eventsourcing.DefaultClock
+	}
+	if config.Logger == nil {
+		config.Logger = eventsourcing.NopLogger{}
+	}
+	return &CircuitBreaker{config: config}
+}
+
+// State | 현재 상태
+func (b *CircuitBreaker) State() CircuitState {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	return b.state
+}
+
+// Allow | 호출해도 되는지 확인한다. 막혀있으면 ErrCircuitOpen 을 돌려준다.
+func (b *CircuitBreaker) Allow() error {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.config.Clock.Now().Sub(b.openedAt) < b.config.OpenTimeout {
+			return ErrCircuitOpen
+		}
+		b.state, b.trying = CircuitHalfOpen, true // 시험 호출 하나만 허용
+		return nil
+	case CircuitHalfOpen:
+		if b.trying {
+			return ErrCircuitOpen // 시험 호출의 결과를 기다리는 중
+		}
+		b.trying = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record | 호출 결과를 기록한다.
+func (b *CircuitBreaker) Record(success bool) {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+
+	b.trying = false
+	if success {
+		if b.state != CircuitClosed {
+			b.config.Logger.Log(eventsourcing.LevelInfo, "storage circuit closed")
+		}
+		b.state, b.failures = CircuitClosed, 0
+		return
+	}
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.config.FailureThreshold {
+		if b.state != CircuitOpen {
+			b.config.Logger.Log(eventsourcing.LevelError, "storage circuit opened", eventsourcing.F("failures", b.failures))
+		}
+		b.state, b.openedAt = CircuitOpen, b.config.Clock.Now()
+	}
+}
@@ -0,0 +1,158 @@
+package retry
+
+import (
+	"encoding/json"
+	"errors"
+	"eventsourcing"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Storage Retry
+//
+// storage 의 일시적인 장애(timeout, throttling, 연결 끊김)는 다시 시도하면 성공하는 경우가 많다.
+// Retrier 는 storage 호출을 감싸서 아래의 규칙으로 다시 시도한다.
+// - Classifier 가 일시적인 에러라고 판단한 에러만 다시 시도한다.
+// - 다시 시도하기 전에 exponential backoff + jitter 만큼 기다린다.
+// - MaxAttempts 만큼 시도해도 실패하면 마지막 에러를 돌려준다.
+// - CircuitBreaker 를 지정하면, storage 가 계속 실패할 때 호출하지 않고 바로 ErrCircuitOpen 을 돌려준다.
+//
+// EventStorage, StateSnapshotStorage 를 감싸는 decorator 는 storage.go 참고.
+
+// Config | Retrier 설정
+type Config struct {
+	MaxAttempts int                          // default 3, 첫 시도를 포함한 최대 시도 횟수
+	BaseDelay   time.Duration                // default 50ms, 첫번째 재시도 전에 기다리는 시간
+	MaxDelay    time.Duration                // default 2s, 기다리는 시간의 최대값
+	Multiplier  float64                      // default 2, 재시도 마다 기다리는 시간을 늘리는 배수
+	Jitter      float64                      // 0 ~ 1 사이, 0 이면 무작위로 줄이지 않고 범위를 벗어나면 0.2, 기다리는 시간을 이 비율 안에서 무작위로 줄여 동시에 재시도가 몰리지 않게 한다.
+	Classifier  func(err error) bool         // default DefaultClassifier, 다시 시도할 에러인지 판단
+	Breaker     *CircuitBreaker              // nullable, storage 가 계속 실패할 때 호출을 막는 circuit breaker
+	Sleep       func(d time.Duration)        // default time.Sleep, 테스트에서 기다리지 않도록 바꿀 때 사용
+	Random      *rand.Rand                   // nullable, jitter 에 사용할 random
+	OnRetry     func(attempt int, err error) // nullable, 다시 시도하기 전에 호출
+	Logger      eventsourcing.Logger         // default eventsourcing.NopLogger, 다시 시도할 때 Warn, 끝내 실패하면 Error 로 남긴다.
+}
+
+// Retrier | 일시적인 에러를 다시 시도하는 실행기
+type Retrier struct {
+	config       Config
+	randomLocker sync.Mutex
+}
+
+func NewRetrier(config Config) *Retrier {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = 50 * time.Millisecond
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = 2 * time.Second
+	}
+	if config.Multiplier < 1 {
+		config.Multiplier = 2
+	}
+	if config.Jitter < 0 || config.Jitter > 1 {
+		config.Jitter = 0.2
+	}
+	if config.Classifier == nil {
+		config.Classifier = DefaultClassifier
+	}
+	if config.Sleep == nil {
+		config.Sleep = time.Sleep
+	}
+	if config.Random == nil {
+		config.Random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	if config.Logger == nil {
+		config.Logger = eventsourcing.NopLogger{}
+	}
+	return &Retrier{config: config}
+}
+
+// DefaultClassifier | 다시 시도할 에러인지 판단하는 기본 규칙
+// - 에러 체인에 eventsourcing.Retryable 을 구현한 에러가 있으면 그 판단을 따른다. (ErrCircuitOpen 은 다시 시도하지 않음)
+// - EventSourceError 라면 eventsourcing.IsRetryable 을 따른다.
+// - json 으로 변환하지 못한 에러는 다시 시도해도 같은 결과이므로 다시 시도하지 않는다.
+// - 그 외 storage 에서 올라온 에러는 일시적인 에러로 본다.
+// storage 가 돌려주는 영구적인 에러(ErrEventIdConflict, integrity.ErrAppendOnly, 복호화 실패 등)는 eventsourcing.Permanent 로 표시되어 있다.
+func DefaultClassifier(err error) bool {
+	var r eventsourcing.Retryable
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+	var esErr *eventsourcing.EventSourceError
+	if errors.As(err, &esErr) {
+		return eventsourcing.IsRetryable(err)
+	}
+	return !isJsonError(err)
+}
+
+func isJsonError(err error) bool {
+	var (
+		syntax      *json.SyntaxError
+		unmarshal   *json.UnmarshalTypeError
+		unsupported *json.UnsupportedTypeError
+		value       *json.UnsupportedValueError
+		marshaler   *json.MarshalerError
+	)
+	return errors.As(err, &syntax) || errors.As(err, &unmarshal) || errors.As(err, &unsupported) ||
+		errors.As(err, &value) || errors.As(err, &marshaler)
+}
+
+// Do | op 를 실행하고, 일시적인 에러라면 다시 시도한다.
+func (r *Retrier) Do(op func() error) error {
+	for attempt := 1; ; attempt++ {
+		if breaker := r.config.Breaker; breaker != nil {
+			if err := breaker.Allow(); err != nil {
+				return err
+			}
+		}
+
+		err := op()
+		transient := err != nil && r.config.Classifier(err)
+		if breaker := r.config.Breaker; breaker != nil {
+			breaker.Record(!transient) // 영구적인 에러는 storage 가 응답한 것이므로 실패로 세지 않음
+		}
+		if !transient {
+			return err
+		}
+		if attempt >= r.config.MaxAttempts {
+			r.config.Logger.Log(eventsourcing.LevelError, "storage call failed after retries", append([]eventsourcing.Field{eventsourcing.F("attempts", attempt)}, eventsourcing.ErrorFields(err)...)...)
+			return err
+		}
+
+		if r.config.OnRetry != nil {
+			r.config.OnRetry(attempt, err)
+		}
+		delay := r.backoff(attempt)
+		r.config.Logger.Log(eventsourcing.LevelWarn, "storage call failed, retrying", append([]eventsourcing.Field{eventsourcing.F("attempt", attempt), eventsourcing.F("delay", delay.String())}, eventsourcing.ErrorFields(err)...)...)
+		r.config.Sleep(delay)
+	}
+}
+
+// backoff | attempt 번째 시도가 실패한 뒤 기다릴 시간
+func (r *Retrier) backoff(attempt int) time.Duration {
+	delay := float64(r.config.BaseDelay) * math.Pow(r.config.Multiplier, float64(attempt-1))
+	if delay > float64(r.config.MaxDelay) {
+		delay = float64(r.config.MaxDelay)
+	}
+	if r.config.Jitter > 0 {
+		r.randomLocker.Lock()
+		delay -= delay * r.config.Jitter * r.config.Random.Float64()
+		r.randomLocker.Unlock()
+	}
+	return time.Duration(delay)
+}
+
+// Get | 값을 돌려주는 op 를 Retrier 로 실행한다.
+func Get[T any](r *Retrier, op func() (T, error)) (result T, err error) {
+	err = r.Do(func() error {
+		result, err = op()
+		return err
+	})
+	return result, err
+}
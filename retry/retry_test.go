@@ -0,0 +1,180 @@
+package retry
+
+import (
+	"encoding/json"
+	"errors"
+	es "eventsourcing"
+	"eventsourcing/integrity"
+	"eventsourcing/memory"
+	"testing"
+	"time"
+)
+
+type permanentError struct{}
+
+func (permanentError) Error() string   { return "permanent" }
+func (permanentError) Retryable() bool { return false }
+
+// flakyEventStorage | 앞의 failures 번의 호출은 실패하는 storage
+type flakyEventStorage struct {
+	*memory.EventStorage[string]
+	failures int
+	calls    int
+	err      error
+}
+
+func (f *flakyEventStorage) AddEvent(e *es.Event[string]) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return f.EventStorage.AddEvent(e)
+}
+
+func newTestRetrier(config Config, sleeps *[]time.Duration) *Retrier {
+	config.Sleep = func(d time.Duration) { *sleeps = append(*sleeps, d) }
+	return NewRetrier(config)
+}
+
+func TestRetrier_Backoff(t *testing.T) {
+	var sleeps []time.Duration
+	r := newTestRetrier(Config{MaxAttempts: 5, BaseDelay: 10 * time.Millisecond, MaxDelay: 30 * time.Millisecond, Jitter: 0}, &sleeps)
+
+	attempts := 0
+	err := r.Do(func() error {
+		attempts++
+		return errors.New("timeout")
+	})
+	if err == nil || attempts != 5 {
+		t.Fatalf("attempts = %d, err = %v", attempts, err)
+	}
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond, 30 * time.Millisecond}
+	if len(sleeps) != len(want) {
+		t.Fatalf("sleeps = %v, want %v", sleeps, want)
+	}
+	for i := range want {
+		if sleeps[i] != want[i] {
+			t.Errorf("sleeps[%d] = %s, want %s", i, sleeps[i], want[i])
+		}
+	}
+
+	// jitter 는 기다리는 시간을 줄이기만 한다
+	sleeps = nil
+	r = newTestRetrier(Config{MaxAttempts: 2, BaseDelay: 100 * time.Millisecond, Jitter: 0.5}, &sleeps)
+	_ = r.Do(func() error { return errors.New("timeout") })
+	if len(sleeps) != 1 || sleeps[0] < 50*time.Millisecond || sleeps[0] > 100*time.Millisecond {
+		t.Errorf("sleeps = %v", sleeps)
+	}
+}
+
+func TestEventStorage_Retry(t *testing.T) {
+	var sleeps []time.Duration
+	r := newTestRetrier(Config{MaxAttempts: 3}, &sleeps)
+	flaky := &flakyEventStorage{EventStorage: memory.NewEventStorage[string](), failures: 2, err: errors.New("timeout")}
+	storage := NewEventStorage[string](flaky, r)
+
+	e := es.NewEvent[string]("pk", &es.EventType{Domain: "test", Name: "retry", Version: "v1"}, 1, nil)
+	if err := storage.AddEvent(e); err != nil {
+		t.Fatal(err)
+	}
+	if flaky.calls != 3 || len(sleeps) != 2 {
+		t.Errorf("calls = %d, sleeps = %v", flaky.calls, sleeps)
+	}
+	if _, ok := storage.(es.EventIdRangeStorage[string]); !ok {
+		t.Error("EventIdRangeStorage must be passed through")
+	}
+
+	// 영구적인 에러는 다시 시도하지 않음
+	flaky.calls, flaky.failures, flaky.err = 0, 1, permanentError{}
+	if err := storage.AddEvent(e); !errors.Is(err, permanentError{}) || flaky.calls != 1 {
+		t.Errorf("calls = %d, err = %v", flaky.calls, err)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := es.NewFakeClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	breaker := NewCircuitBreaker(BreakerConfig{FailureThreshold: 3, OpenTimeout: time.Minute, Clock: clock})
+	var sleeps []time.Duration
+	r := newTestRetrier(Config{MaxAttempts: 2, Breaker: breaker}, &sleeps)
+
+	calls := 0
+	failing := func() error {
+		calls++
+		return errors.New("timeout")
+	}
+	_ = r.Do(failing) // 2번 실패
+	_ = r.Do(failing) // 3번째 실패에서 열림
+	if breaker.State() != CircuitOpen || calls != 3 {
+		t.Fatalf("state = %d, calls = %d", breaker.State(), calls)
+	}
+	if err := r.Do(failing); !errors.Is(err, ErrCircuitOpen) || calls != 3 {
+		t.Errorf("open circuit must not call. err = %v, calls = %d", err, calls)
+	}
+
+	// OpenTimeout 이 지나면 한 번 시험 호출, 성공하면 닫힘
+	clock.Advance(time.Minute)
+	if err := r.Do(func() error { calls++; return nil }); err != nil || breaker.State() != CircuitClosed {
+		t.Errorf("err = %v, state = %d", err, breaker.State())
+	}
+
+	// 영구적인 에러는 실패로 세지 않음
+	for i := 0; i < 5; i++ {
+		_ = r.Do(func() error { return permanentError{} })
+	}
+	if breaker.State() != CircuitClosed {
+		t.Errorf("state = %d, want closed", breaker.State())
+	}
+}
+
+func TestDefaultClassifier_Permanent(t *testing.T) {
+	breaker := NewCircuitBreaker(BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	var sleeps []time.Duration
+	r := newTestRetrier(Config{MaxAttempts: 3, Breaker: breaker}, &sleeps)
+
+	// 같은 EventId 를 다른 payload 로 저장
+	counted := &flakyEventStorage{EventStorage: memory.NewEventStorage[string]()}
+	storage := NewEventStorage[string](counted, r)
+	first := "first"
+	e := es.NewEvent[string]("pk", &es.EventType{Domain: "test", Name: "retry", Version: "v1"}, 1, &first)
+	if err := storage.AddEvent(e); err != nil {
+		t.Fatal(err)
+	}
+	conflict := *e
+	second := "second"
+	conflict.Request = &second
+	for i := 0; i < 3; i++ {
+		if err := storage.AddEvent(&conflict); !errors.Is(err, es.ErrEventIdConflict) {
+			t.Fatalf("err = %v, want ErrEventIdConflict", err)
+		}
+	}
+	if counted.calls != 4 || len(sleeps) != 0 || breaker.State() != CircuitClosed {
+		t.Errorf("calls = %d, sleeps = %v, state = %d", counted.calls, sleeps, breaker.State())
+	}
+
+	// hash chain 에 AddEvent
+	chained := NewEventStorage[string](integrity.NewEventStorage[string](memory.NewEventStorage[string]()), r)
+	for i := 0; i < 3; i++ {
+		if err := chained.AddEvent(e); !errors.Is(err, integrity.ErrAppendOnly) {
+			t.Fatalf("err = %v, want ErrAppendOnly", err)
+		}
+	}
+	if len(sleeps) != 0 || breaker.State() != CircuitClosed {
+		t.Errorf("sleeps = %v, state = %d", sleeps, breaker.State())
+	}
+
+	var v any
+	if DefaultClassifier(json.Unmarshal([]byte("{"), &v)) {
+		t.Error("json error must not be retried")
+	}
+}
+
+func TestSnapshotStorage_PassThroughHistory(t *testing.T) {
+	r := NewRetrier(Config{})
+	type state struct{ es.CommonState[string] }
+	if _, ok := NewSnapshotStorage[state, string](memory.NewSnapshotStorage[state, string](), r).(es.StateSnapshotHistoryStorage[state, string]); ok {
+		t.Error("SnapshotStorage must not implement history")
+	}
+	if _, ok := NewSnapshotStorage[state, string](memory.NewSnapshotHistoryStorage[state, string](nil), r).(es.StateSnapshotHistoryStorage[state, string]); !ok {
+		t.Error("SnapshotHistoryStorage must be passed through")
+	}
+}
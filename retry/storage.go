@@ -0,0 +1,100 @@
+package retry
+
+import (
+	es "eventsourcing"
+	"time"
+)
+
+// Storage Decorator
+//
+// EventStorage, StateSnapshotStorage 의 모든 호출을 Retrier 로 감싼다.
+// 감싼 storage 의 선택 인터페이스는 eventsourcing.DecorateEventStorage, DecorateSnapshotStorage 로 이어받고, 그 호출도 Retrier 로 감싼다.
+//
+// 주의) 쓰기 호출은 timeout 처럼 성공 여부를 모르는 실패도 다시 시도한다.
+// - AddEvent 는 같은 EventId 로 다시 저장되므로, storage 는 같은 EventId 의 같은 내용 저장을 무시해야 한다.
+// - AppendEvent 는 같은 EventId 로 다시 호출되므로, 먼저 저장된 event 가 appended=false 로 돌아올 수 있다.
+// - IncreaseEventNo 는 발급된 번호를 잃어버릴 수 있으므로 eventNo 에 빈 번호가 생길 수 있다.
+
+var _ es.EventStorage[any] = &EventStorage[any]{}
+
+// EventStorage | 호출을 Retrier 로 감싼 EventStorage
+type EventStorage[R any] struct {
+	storage es.EventStorage[R]
+	retrier *Retrier
+}
+
+// NewEventStorage | storage 의 호출을 retrier 로 감싼다.
+func NewEventStorage[R any](storage es.EventStorage[R], retrier *Retrier) es.EventStorage[R] {
+	return es.DecorateEventStorage[R](
+		&EventStorage[R]{storage: storage, retrier: retrier},
+		storage,
+		es.EventStorageHooks[R]{Call: retrier.Do},
+	)
+}
+
+func (a *EventStorage[R]) IncreaseEventNo(pk es.PartitionKey) (int, error) {
+	return Get(a.retrier, func() (int, error) { return a.storage.IncreaseEventNo(pk) })
+}
+
+func (a *EventStorage[R]) AddEvent(e *es.Event[R]) error {
+	return a.retrier.Do(func() error { return a.storage.AddEvent(e) })
+}
+
+func (a *EventStorage[R]) AppendEvent(ea *es.EventAppend[R]) (e *es.Event[R], appended bool, err error) {
+	err = a.retrier.Do(func() error {
+		e, appended, err = a.storage.AppendEvent(ea)
+		return err
+	})
+	return e, appended, err
+}
+
+func (a *EventStorage[R]) GetEvent(id es.EventId) (*es.Event[R], error) {
+	return Get(a.retrier, func() (*es.Event[R], error) { return a.storage.GetEvent(id) })
+}
+
+func (a *EventStorage[R]) GetEvents(pk es.PartitionKey) ([]*es.Event[R], error) {
+	return Get(a.retrier, func() ([]*es.Event[R], error) { return a.storage.GetEvents(pk) })
+}
+
+func (a *EventStorage[R]) GetEventsAfterEventNo(pk es.PartitionKey, eno int) ([]*es.Event[R], error) {
+	return Get(a.retrier, func() ([]*es.Event[R], error) { return a.storage.GetEventsAfterEventNo(pk, eno) })
+}
+
+func (a *EventStorage[R]) GetLastEvent(pk es.PartitionKey) (*es.Event[R], error) {
+	return Get(a.retrier, func() (*es.Event[R], error) { return a.storage.GetLastEvent(pk) })
+}
+
+func (a *EventStorage[R]) GetEventByIdempotencyKey(pk es.PartitionKey, key string, since time.Time) (*es.Event[R], error) {
+	return Get(a.retrier, func() (*es.Event[R], error) { return a.storage.GetEventByIdempotencyKey(pk, key, since) })
+}
+
+func (a *EventStorage[R]) ListPartitions(cursor string, limit int) (stats []*es.PartitionStat, next string, err error) {
+	err = a.retrier.Do(func() error {
+		stats, next, err = a.storage.ListPartitions(cursor, limit)
+		return err
+	})
+	return stats, next, err
+}
+
+// SnapshotStorage | 호출을 Retrier 로 감싼 StateSnapshotStorage
+type SnapshotStorage[S es.CommonState[R], R any] struct {
+	storage es.StateSnapshotStorage[S, R]
+	retrier *Retrier
+}
+
+// NewSnapshotStorage | storage 의 호출을 retrier 로 감싼다.
+func NewSnapshotStorage[S es.CommonState[R], R any](storage es.StateSnapshotStorage[S, R], retrier *Retrier) es.StateSnapshotStorage[S, R] {
+	return es.DecorateSnapshotStorage[S, R](
+		&SnapshotStorage[S, R]{storage: storage, retrier: retrier},
+		storage,
+		es.SnapshotStorageHooks[S, R]{Call: retrier.Do},
+	)
+}
+
+func (a *SnapshotStorage[S, R]) SaveSnapshot(pk es.PartitionKey, state *es.State[S, R]) error {
+	return a.retrier.Do(func() error { return a.storage.SaveSnapshot(pk, state) })
+}
+
+func (a *SnapshotStorage[S, R]) GetSnapshot(pk es.PartitionKey) (*es.State[S, R], error) {
+	return Get(a.retrier, func() (*es.State[S, R], error) { return a.storage.GetSnapshot(pk) })
+}
@@ -9,8 +9,21 @@ import (
 type Rule struct {
 	// snapshot 저장 규칙
 	AlwaysSnapshot  *bool          // default false, 항상 snapshot 을 최신으로 유지하는지 여부
-	MinSnapshotTerm *time.Duration // default 1 min, 최근 eventAt 과 snapshot eventAt 의  최소 시간 차이. 이 값을 넘어가면 snapshot 을 저장한다.
+	MinSnapshotTerm *time.Duration // default 1 min, 현재 시간(Clock)과 snapshot eventAt 의 최소 시간 차이. 이 값을 넘어가면 snapshot 을 저장한다.
 	MinEventNoTerm  *int           // default 5, 최근 eventNo 와 snapshot 의 eventNo 와 최소 차이. 이 값을 넘어가면 snapshot 을 저장한다.
+
+	// 멱등 Put 규칙
+	IdempotencyWindow *time.Duration // default 24 hour, 멱등키를 기억하는 기간. 이 기간이 지난 키로 Put 하면 새 event 로 저장한다.
+
+	// replay 실패 규칙
+	FailurePolicy  *FailurePolicy // default HaltOnFailure, replay 중 event 적용에 실패했을 때의 처리 방식
+	FailureRetries *int           // default 0, FailurePolicy 를 따르기 전에 실패한 event 를 다시 시도하는 횟수
+
+	// crypto-shredding 규칙
+	ShreddedPolicy *ShreddedPolicy // default SkipShredded, replay 중 Request 를 읽을 수 없는 event 의 처리 방식
+
+	// 서명 검증 규칙, manager 에 SignatureVerifier 를 지정했을 때만 검증한다
+	SignaturePolicy *SignaturePolicy // default RejectInvalidSignature, replay, 조회 중 서명이 맞지 않는 event 의 처리 방식
 }
 
 // Merge | Rule 을 병합
@@ -25,6 +38,21 @@ func (r *Rule) Merge(rule *Rule) {
 		if rule.MinEventNoTerm != nil {
 			r.MinEventNoTerm = rule.MinEventNoTerm
 		}
+		if rule.IdempotencyWindow != nil {
+			r.IdempotencyWindow = rule.IdempotencyWindow
+		}
+		if rule.FailurePolicy != nil {
+			r.FailurePolicy = rule.FailurePolicy
+		}
+		if rule.FailureRetries != nil {
+			r.FailureRetries = rule.FailureRetries
+		}
+		if rule.ShreddedPolicy != nil {
+			r.ShreddedPolicy = rule.ShreddedPolicy
+		}
+		if rule.SignaturePolicy != nil {
+			r.SignaturePolicy = rule.SignaturePolicy
+		}
 	}
 }
 
@@ -34,5 +62,46 @@ func NewDefaultRule() *Rule {
 		AlwaysSnapshot:  ptr.Bool(false),
 		MinSnapshotTerm: ptr.Duration(1 * time.Minute),
 		MinEventNoTerm:  ptr.Int(5),
+
+		IdempotencyWindow: ptr.Duration(24 * time.Hour),
+
+		FailurePolicy:  FailurePolicyPtr(HaltOnFailure),
+		FailureRetries: ptr.Int(0),
+
+		ShreddedPolicy: ShreddedPolicyPtr(SkipShredded),
+
+		SignaturePolicy: SignaturePolicyPtr(RejectInvalidSignature),
+	}
+}
+
+// FailurePolicyPtr | Rule 에 넣을 FailurePolicy 의 pointer
+func FailurePolicyPtr(p FailurePolicy) *FailurePolicy {
+	return &p
+}
+
+// ShreddedPolicyPtr | Rule 에 넣을 ShreddedPolicy 의 pointer
+func ShreddedPolicyPtr(p ShreddedPolicy) *ShreddedPolicy {
+	return &p
+}
+
+// SignaturePolicyPtr | Rule 에 넣을 SignaturePolicy 의 pointer
+func SignaturePolicyPtr(p SignaturePolicy) *SignaturePolicy {
+	return &p
+}
+
+// NeedSnapshot | snapshot 을 새로 저장해야 하는지 판단한다. snapshot 이 없으면(snapshotEventNo 가 0) 항상 저장한다.
+func (r *Rule) NeedSnapshot(clock Clock, snapshotEventNo int, snapshotEventAt time.Time, latestEventNo int) bool {
+	if snapshotEventNo == 0 {
+		return true
+	}
+	if r.AlwaysSnapshot != nil && *r.AlwaysSnapshot {
+		return true
+	}
+	if r.MinEventNoTerm != nil && latestEventNo-snapshotEventNo > *r.MinEventNoTerm {
+		return true
+	}
+	if r.MinSnapshotTerm != nil && clock.Now().Sub(snapshotEventAt) > *r.MinSnapshotTerm {
+		return true
 	}
+	return false
 }
@@ -0,0 +1,39 @@
+package eventsourcing
+
+import "errors"
+
+// Crypto-Shredding
+//
+// append-only 인 Event Storage 에서는 개인정보 삭제 요청이 와도 event 를 지울 수 없다.
+// 대신 partition 마다 data key 로 Event.Request 와 snapshot 을 암호화해서 저장하고, 삭제 요청이 오면 KeyStore 에서 key 를 지운다.
+// key 가 지워진 partition 의 event 는 Request 를 읽을 수 없는 상태(Shredded)로 조회된다.
+// Rule.ShreddedPolicy 로 replay 중 Shredded event 를 어떻게 다룰지 정한다.
+// - SkipShredded : Process 를 수행하지 않고 건너뛴다. state 가 LastEventSetter 를 구현하면 마지막 이벤트로 기록한다. (기본)
+// - HaltOnShredded : replay 를 멈추고 ErrShreddedEvent 를 원인으로 하는 CommandError 를 돌려준다.
+// - ApplyShredded : Request 가 nil 인 event 로 Process 를 수행한다. Process 가 직접 판단한다.
+// 암호화는 encryption package 의 storage decorator 가 담당한다.
+
+var (
+	ErrKeyShredded   = Permanent(errors.New("data key is shredded")) // KeyStore 에 key 가 없음, 지워졌거나 만든 적이 없음
+	ErrShreddedEvent = errors.New("event is shredded")               // Request 를 읽을 수 없는 event 를 replay 함
+)
+
+// ShreddedPolicy | replay 중 Request 를 읽을 수 없는 event 를 만났을 때의 처리 방식
+type ShreddedPolicy int
+
+const (
+	SkipShredded   ShreddedPolicy = iota // Process 를 수행하지 않고 건너뜀
+	HaltOnShredded                       // replay 를 멈춤
+	ApplyShredded                        // Request 가 nil 인 event 로 Process 를 수행
+)
+
+// EncryptedPayload | 암호화된 Request 나 State
+type EncryptedPayload struct {
+	KeyId string `json:"keyId"` // 암호화에 사용한 data key 의 id
+	Data  []byte `json:"data"`  // nonce + 암호문
+}
+
+// Shredded | Request 가 암호화된 채로 남아 있는지 여부, data key 가 지워진 event 는 복호화되지 않는다.
+func (e *Event[R]) Shredded() bool {
+	return e != nil && e.Request == nil && e.Encrypted != nil
+}
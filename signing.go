@@ -0,0 +1,104 @@
+package eventsourcing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Event Signing
+//
+// 여러 서비스가 하나의 Event Storage 에 event 를 쌓을 때, 누가 event 를 만들었는지 확인할 수 있도록 Put 에서 event 에 서명한다.
+// 서명은 저장소가 번호를 발급하고 암호화까지 마친 저장 직전(EventAppend.Seal)에, envelope(Request 를 뺀 event) 과 payload 의 digest 에 건다.
+// 서명에 사용한 key 의 id 를 Event.KeyId 에 남기므로, key 를 바꾸더라도(rotation) 이전 key 로 서명한 event 를 검증할 수 있다.
+// Rule.SignaturePolicy 로 replay, 조회 중 서명이 맞지 않는 event 를 어떻게 다룰지 정한다.
+// - RejectInvalidSignature : 에러를 돌려준다. (기본)
+// - WarnInvalidSignature : Warn 로그를 남기고 계속한다.
+// - IgnoreSignature : 검증하지 않는다.
+// 서명이 없는 event 도 맞지 않는 것으로 본다.
+// 암호화한 event 의 digest 는 암호문으로 계산하므로, key 를 지워 Request 를 읽을 수 없는(Shredded) event 도 검증한다.
+// Ed25519, HMAC 구현과 key rotation 은 integrity package 가 담당한다.
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrMissingSignature = errors.New("missing signature")
+)
+
+// SignaturePolicy | replay, 조회 중 서명이 맞지 않는 event 를 만났을 때의 처리 방식
+type SignaturePolicy int
+
+const (
+	RejectInvalidSignature SignaturePolicy = iota // 에러를 돌려줌
+	WarnInvalidSignature                          // Warn 로그를 남기고 계속함
+	IgnoreSignature                               // 검증하지 않음
+)
+
+// Signer | data 에 서명하는 인터페이스
+type Signer interface {
+	Sign(data []byte) (keyId string, signature []byte, err error) // data 의 서명과, 서명에 사용한 key 의 id
+}
+
+// SignatureVerifier | keyId 의 key 로 서명을 검증하는 인터페이스
+type SignatureVerifier interface {
+	Verify(keyId string, data, signature []byte) error // 서명이 맞지 않으면 ErrInvalidSignature, keyId 를 모르면 ErrUnknownKey
+}
+
+// EventSigningBytes | event 의 서명 대상, envelope 의 json 과 PayloadDigest 를 이어붙인 값
+// envelope 은 Request, Encrypted 와 서명(KeyId, Signature), 저장소가 채우는 값(Hash, PrevHash)을 뺀 event 이다.
+func EventSigningBytes[R any](e *Event[R]) ([]byte, error) {
+	envelope := *e
+	envelope.Request, envelope.Encrypted = nil, nil
+	envelope.KeyId, envelope.Signature = "", nil
+	envelope.Hash, envelope.PrevHash = "", ""
+	b, err := json.Marshal(&envelope)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := PayloadDigest(e)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(b, '\n'), digest...), nil
+}
+
+// PayloadDigest | event 내용의 SHA-256 hex 문자열, 암호화한 event 는 암호문(Encrypted), 아니면 Request 의 json 으로 계산한다.
+func PayloadDigest[R any](e *Event[R]) (string, error) {
+	var payload any = e.Request
+	if e.Encrypted != nil {
+		payload = e.Encrypted
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SignEvent | signer 로 event 에 서명하고 KeyId, Signature 를 채운다.
+func SignEvent[R any](signer Signer, e *Event[R]) error {
+	data, err := EventSigningBytes(e)
+	if err != nil {
+		return err
+	}
+	e.KeyId, e.Signature, err = signer.Sign(data)
+	return err
+}
+
+// VerifyEvent | event 의 서명을 검증한다.
+func VerifyEvent[R any](verifier SignatureVerifier, e *Event[R]) error {
+	if len(e.Signature) == 0 {
+		return fmt.Errorf("%w. eventId(%s)", ErrMissingSignature, e.EventId)
+	}
+	data, err := EventSigningBytes(e)
+	if err != nil {
+		return err
+	}
+	if err = verifier.Verify(e.KeyId, data, e.Signature); err != nil {
+		return fmt.Errorf("%w. eventId(%s), keyId(%s)", err, e.EventId, e.KeyId)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+//go:build go1.21
+
+package eventsourcing
+
+import (
+	"context"
+	"log/slog"
+)
+
+// slogLogger | log/slog 의 Logger 로 남기는 Logger
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger | log/slog 의 Logger 로 남기는 Logger 를 만든다. logger 가 nil 이면 slog.Default() 를 사용한다.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return slogLogger{logger: logger}
+}
+
+func (l slogLogger) Log(level Level, msg string, fields ...Field) {
+	l.logger.LogAttrs(context.Background(), slogLevel(level), msg, slogAttrs(fields)...)
+}
+
+func (l slogLogger) With(fields ...Field) Logger {
+	attrs := slogAttrs(fields)
+	args := make([]any, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+	return slogLogger{logger: l.logger.With(args...)}
+}
+
+func slogLevel(level Level) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func slogAttrs(fields []Field) []slog.Attr {
+	attrs := make([]slog.Attr, len(fields))
+	for i, f := range fields {
+		attrs[i] = slog.Any(f.Key, f.Value)
+	}
+	return attrs
+}
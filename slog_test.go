@@ -0,0 +1,43 @@
+//go:build go1.21
+
+package eventsourcing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))).
+		With(F(FieldKeyDomain, "test"))
+
+	e := NewEvent[int]("pk", &processTestEventType, 3, nil)
+	logger.Log(LevelWarn, "process failed", ErrorFields(NewCommandError(errors.New("bug"), "pk", e))...)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("%v. log(%s)", err, buf.String())
+	}
+	want := map[string]any{
+		"level":              "WARN",
+		"msg":                "process failed",
+		FieldKeyDomain:       "test",
+		FieldKeyPartitionKey: "pk",
+		FieldKeyEventName:    string(processTestEventType.Name),
+		FieldKeyEventVersion: string(processTestEventType.Version),
+		FieldKeyEventNo:      float64(3),
+		FieldKeyErrorCode:    float64(CommandError),
+	}
+	for k, v := range want {
+		if record[k] != v {
+			t.Errorf("record[%s] = %v, want %v", k, record[k], v)
+		}
+	}
+	if record[FieldKeyError] == nil {
+		t.Error("error message is missing")
+	}
+}
@@ -1,5 +1,9 @@
 package eventsourcing
 
+import (
+	"encoding/json"
+)
+
 // CommonState | Domain 마다 정의하는 State 가 구현해야할 인터페이스, R는 Event 의 Request 구조체 타입
 type CommonState[R any] interface {
 	GetPartitionKey() PartitionKey // 파티션 키를 가져온다
@@ -7,8 +11,21 @@ type CommonState[R any] interface {
 	String() string                // State 의 ToString() func
 }
 
+// LastEventSetter | State 의 마지막 이벤트를 기록하는 인터페이스 (선택)
+// *S 가 구현하면 Processor 가 Process 를 수행한 뒤 적용한 이벤트를 직접 기록하므로, Process 에서 LastEvent 를 기록하지 않아도 된다.
+type LastEventSetter[R any] interface {
+	SetLastEvent(event *Event[R])
+}
+
+// Cloner | State 의 deep copy 를 직접 구현할 때 사용하는 인터페이스 (선택)
+// S 나 *S 가 Cloner 를 구현하지 않으면 State.Clone 은 json 으로 복사하므로, json 으로 표현되지 않는 field 가 있다면 구현해야 한다.
+type Cloner[S any] interface {
+	Clone() *S
+}
+
 type State[S CommonState[R], R any] struct {
-	state *S
+	state  *S
+	sealed *EncryptedPayload // 암호화해서 저장한 state, 이 값이 있으면 state 는 자리만 차지하는 값
 }
 
 func NewState[S CommonState[R], R any](state *S) *State[S, R] {
@@ -17,10 +34,48 @@ func NewState[S CommonState[R], R any](state *S) *State[S, R] {
 	}
 }
 
+// NewSealedState | 암호화된 state 를 담는 State, placeholder 는 storage 가 읽을 수 있는 값(LastEvent 등)만 채운 state
+func NewSealedState[S CommonState[R], R any](placeholder *S, sealed *EncryptedPayload) *State[S, R] {
+	return &State[S, R]{
+		state:  placeholder,
+		sealed: sealed,
+	}
+}
+
 func (s *State[S, R]) State() *S {
 	return s.state
 }
 
+// Sealed | 암호화된 state, 암호화되지 않았으면 nil
+func (s *State[S, R]) Sealed() *EncryptedPayload {
+	return s.sealed
+}
+
 func (s *State[S, R]) String() string {
 	return (*s.state).String()
 }
+
+// Clone | State 를 deep copy 한다. 복사본을 바꿔도 원본은 바뀌지 않는다.
+// Process 는 state 를 제자리에서 바꾸므로, 저장된 snapshot 에 replay 할 때는 복사본에 replay 해야 한다.
+func (s *State[S, R]) Clone() (*State[S, R], error) {
+	if s == nil || s.state == nil {
+		return s, nil
+	}
+	if cloner, ok := any(s.state).(Cloner[S]); ok {
+		return NewSealedState[S, R](cloner.Clone(), s.sealed), nil
+	}
+	if cloner, ok := any(*s.state).(Cloner[S]); ok {
+		return NewSealedState[S, R](cloner.Clone(), s.sealed), nil
+	}
+
+	// Cloner 를 구현하지 않았으면 json 으로 복사
+	b, err := json.Marshal(s.state)
+	if err != nil {
+		return nil, err
+	}
+	cloned := new(S)
+	if err = json.Unmarshal(b, cloned); err != nil {
+		return nil, err
+	}
+	return NewSealedState[S, R](cloned, s.sealed), nil
+}
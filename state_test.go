@@ -0,0 +1,62 @@
+package eventsourcing
+
+import "testing"
+
+type cloneTestState struct {
+	PartitionKey PartitionKey `json:"partitionKey"`
+	Tags         []string     `json:"tags"`
+	LastEvent    *Event[int]  `json:"lastEvent"`
+}
+
+func (s cloneTestState) GetPartitionKey() PartitionKey { return s.PartitionKey }
+func (s cloneTestState) GetLastEvent() *Event[int]     { return s.LastEvent }
+func (s cloneTestState) String() string                { return JsonString(s) }
+
+type clonerTestState struct {
+	cloneTestState
+	cloned bool
+}
+
+func (s *clonerTestState) Clone() *clonerTestState {
+	return &clonerTestState{
+		cloneTestState: cloneTestState{PartitionKey: s.PartitionKey, Tags: append([]string(nil), s.Tags...)},
+		cloned:         true,
+	}
+}
+
+func TestState_Clone(t *testing.T) {
+	// Cloner 를 구현하지 않으면 json 으로 복사
+	origin := NewState[cloneTestState, int](&cloneTestState{
+		PartitionKey: "pk",
+		Tags:         []string{"a", "b"},
+		LastEvent:    &Event[int]{EventType: &EventType{Domain: "test", Name: "test", Version: "v1"}, EventNo: 3},
+	})
+	cloned, err := origin.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cloned.State().Tags[0] = "changed"
+	cloned.State().LastEvent.EventNo = 4
+	if origin.State().Tags[0] != "a" || origin.State().LastEvent.EventNo != 3 {
+		t.Errorf("origin is changed. %s", origin)
+	}
+	if cloned.State().PartitionKey != "pk" || cloned.State().LastEvent.Name != "test" {
+		t.Errorf("cloned = %s", cloned)
+	}
+
+	// *S 가 Cloner 를 구현하면 Clone 을 사용
+	withCloner := NewState[clonerTestState, int](&clonerTestState{cloneTestState: cloneTestState{Tags: []string{"a"}}})
+	clonedWithCloner, err := withCloner.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !clonedWithCloner.State().cloned {
+		t.Error("Cloner is not used")
+	}
+
+	// nil state 는 그대로
+	var empty *State[cloneTestState, int]
+	if cloned, err = empty.Clone(); cloned != nil || err != nil {
+		t.Errorf("nil clone = %v, %v", cloned, err)
+	}
+}
@@ -17,14 +17,60 @@
 
 package eventsourcing
 
+import (
+	"errors"
+	"time"
+)
+
+// ErrEventIdConflict | 이미 저장된 EventId 를 다른 내용으로 저장하려 함
+var ErrEventIdConflict = Permanent(errors.New("event id is already stored with a different payload"))
+
 // EventStorage | Event 저장소의 인터페이스
 type EventStorage[R any] interface {
 	IncreaseEventNo(pk PartitionKey) (eno int, err error)                // atomic 하게 event 번호를 증가시켜 가져온다. pk가 처음 들어오는 것이면 1을 리턴
-	AddEvent(e *Event[R]) error                                          // event 를 저장
+	AddEvent(e *Event[R]) error                                          // event 를 저장, 같은 EventId 를 같은 내용으로 다시 저장하면 무시하고 다른 내용이면 ErrEventIdConflict
 	GetEvent(id EventId) (*Event[R], error)                              // event 를 조회
 	GetEvents(pk PartitionKey) ([]*Event[R], error)                      // partition key 의 전체 event list 를 조회
 	GetEventsAfterEventNo(pk PartitionKey, eno int) ([]*Event[R], error) // partition key 의 eventNo 보다 큰 events 를 조회
 	GetLastEvent(pk PartitionKey) (*Event[R], error)                     // partition key 의 마지막 event 를 조회
+
+	// partition key 의 다음 eventNo 를 발급해서 event 를 저장하고, 저장된 event 를 돌려준다.
+	// 번호 발급, Seal, 저장은 같은 pk 의 다른 AppendEvent 와 섞이지 않아야 한다. (pk 잠금이나 마지막 event 가 그대로일 때만 쓰는 조건부 쓰기)
+	// 같은 EventId 가 이미 저장되어 있으면(ex. 재시도) 저장하지 않고 저장된 event 를 appended=false 로 돌려준다.
+	// 멱등키가 있으면 번호 발급 전에 같은 잠금(조건) 안에서 IdempotencySince 이후 같은 키로 저장된 event 를 찾고, 있으면 그 event 를 appended=false 로 돌려준다.
+	AppendEvent(ea *EventAppend[R]) (event *Event[R], appended bool, err error)
+
+	// partition key 에 since 이후 같은 멱등키로 저장된 event 를 조회, 없으면 nil
+	// since 이전에 저장된 키는 더 이상 기억하지 않아도 되지만, 지우는 일은 조회와 따로 한다.
+	GetEventByIdempotencyKey(pk PartitionKey, key string, since time.Time) (*Event[R], error)
+
+	// 저장된 partition 을 pk 순서로 cursor 다음부터 limit 개 조회, 더 없으면 next 는 빈 값
+	// rebuild, export, admin 도구처럼 partition 전체를 훑어야 할 때 사용한다.
+	ListPartitions(cursor string, limit int) (stats []*PartitionStat, next string, err error)
+}
+
+// EventAppend | AppendEvent 로 저장할 event
+type EventAppend[R any] struct {
+	Event            *Event[R] // 저장할 event, EventNo 는 저장소가 발급해서 채운다
+	IdempotencySince time.Time // Event.IdempotencyKey 가 있을 때, 이 시간 이후 같은 키로 저장된 event 만 같은 요청으로 본다
+
+	// nullable, 번호를 채운 event 와 pk 의 마지막 event(없으면 nil) 로 저장 직전에 호출한다.
+	// 서명, hash 처럼 저장되는 모양에 거는 값을 채우고, 에러를 돌려주면 저장하지 않는다. storage 를 다시 호출하면 안 된다.
+	Seal func(e, last *Event[R]) error
+}
+
+// PartitionStat | partition 의 event 통계
+type PartitionStat struct {
+	PartitionKey  PartitionKey `json:"partitionKey"`
+	EventCount    int          `json:"eventCount"`    // 저장된 event 수
+	LastEventNo   int          `json:"lastEventNo"`   // 마지막 event 의 번호
+	LastEventAt   time.Time    `json:"lastEventAt"`   // 마지막 event 의 시간
+	LastEventType *EventType   `json:"lastEventType"` // 마지막 event 의 종류
+}
+
+// EventIdRangeStorage | EventId 범위로 Event 를 조회할 수 있는 저장소의 인터페이스, EventId 가 sorted 하게 발급될 때만 의미가 있다.
+type EventIdRangeStorage[R any] interface {
+	GetEventsBetweenIds(from, to EventId) ([]*Event[R], error) // from <= EventId < to 인 event 를 EventId 순서로 조회, to 가 비어있으면 끝까지
 }
 
 // StateSnapshotStorage | State Snapshot 저장소의 인터페이스
@@ -33,6 +79,45 @@ type StateSnapshotStorage[S CommonState[R], R any] interface {
 	GetSnapshot(pk PartitionKey) (state *State[S, R], err error) // PartitionKey 로 검색하여 현재 Snapshot 조회
 }
 
+// StateSnapshotHistoryStorage | (pk, eventNo) 로 여러 State Snapshot 을 보관하는 저장소의 인터페이스
+// SaveSnapshot 은 snapshot 을 덮어쓰지 않고 history 에 추가하며(같은 eventNo 는 덮어씀), GetSnapshot 은 가장 최근 snapshot 을 조회한다.
+// 보관 기간은 RetentionPolicy 를 따른다.
+type StateSnapshotHistoryStorage[S CommonState[R], R any] interface {
+	StateSnapshotStorage[S, R]
+	GetSnapshotAtOrBefore(pk PartitionKey, eventNo int) (state *State[S, R], err error) // eventNo 이하의 snapshot 중 가장 최근 snapshot 조회, 없으면 nil
+	GetSnapshotHistory(pk PartitionKey) ([]SnapshotInfo, error)                         // 보관중인 snapshot 목록을 eventNo 오름차순으로 조회
+}
+
+// RebuildCheckpointStorage | snapshot rebuild 작업의 진행 상황을 저장하는 인터페이스, 중단된 작업을 이어서 할 때 사용
+type RebuildCheckpointStorage interface {
+	MarkRebuilt(jobId string, pk PartitionKey) error       // jobId 작업에서 pk 의 rebuild 가 끝났음을 기록
+	IsRebuilt(jobId string, pk PartitionKey) (bool, error) // jobId 작업에서 pk 의 rebuild 가 끝났는지 조회
+}
+
+// DeadLetterStorage | replay 에 실패해서 격리된 event 를 보관하는 저장소의 인터페이스
+type DeadLetterStorage[R any] interface {
+	AddDeadLetter(letter *DeadLetter[R]) error                                                   // 격리된 event 를 저장, 같은 (pk, eventNo) 는 덮어씀
+	GetDeadLetters(pk PartitionKey) ([]*DeadLetter[R], error)                                    // partition key 의 격리된 event 를 eventNo 순서로 조회
+	ListDeadLetters(cursor string, limit int) (letters []*DeadLetter[R], next string, err error) // 전체 격리된 event 를 (pk, eventNo) 순서로 cursor 다음부터 limit 개 조회, 더 없으면 next 는 빈 값
+	CorrectDeadLetter(pk PartitionKey, eventNo int, correctedBy EventId) error                   // 격리된 event 를 정정 event 로 처리했음을 기록
+	RemoveDeadLetter(pk PartitionKey, eventNo int) error                                         // 격리를 해제
+}
+
+// KeyStore | partition 별 data key 저장소의 인터페이스, crypto-shredding 에 사용
+// key 는 AES-256 key(32 byte) 이고, 한 번 발급한 keyId 의 key 는 바뀌지 않아야 한다.
+type KeyStore interface {
+	DataKey(pk PartitionKey) (keyId string, key []byte, err error) // 암호화에 사용할 pk 의 key 를 조회, 없으면 새로 만든다
+	GetKey(pk PartitionKey, keyId string) ([]byte, error)          // 복호화에 사용할 key 를 조회, 없으면 ErrKeyShredded
+	DeleteKeys(pk PartitionKey) error                              // pk 의 모든 key 를 삭제, 이후 pk 의 기존 데이터는 복호화할 수 없다
+}
+
+// HashCheckpointStorage | partition 의 chain head 에 서명한 HashCheckpoint 저장소의 인터페이스
+type HashCheckpointStorage interface {
+	SaveCheckpoint(checkpoint *HashCheckpoint) error            // checkpoint 를 저장, 같은 (pk, eventNo) 는 덮어씀
+	GetCheckpoints(pk PartitionKey) ([]*HashCheckpoint, error)  // partition key 의 checkpoint 를 eventNo 순서로 조회
+	GetLastCheckpoint(pk PartitionKey) (*HashCheckpoint, error) // partition key 의 가장 최근 checkpoint 를 조회, 없으면 nil
+}
+
 // LatestEventTypeStorage | 최근 EventType 을 저장하는 인터페이스
 type LatestEventTypeStorage interface {
 	SaveEventType(pk PartitionKey, eid *EventId, et *EventType) // PartitionKey 의 최근 eventType 을 저장
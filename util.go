@@ -1,13 +1,13 @@
 package eventsourcing
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/pkg/errors"
 )
 
+// JsonString | 로그, 에러 메세지 용 json 문자열, `es:"redact"` field 는 가린다. 변환에 실패하면 빈 값
 func JsonString(v any) string {
-	json, err := json.Marshal(v)
+	json, err := RedactedJson(v)
 	if err != nil {
 		return ""
 	}